@@ -15,6 +15,133 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/v1/admin/halt": {
+            "get": {
+                "description": "Report whether trading is halted, why, by whom and since when.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get trading halt state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HaltState"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Halt or resume trading on every exchange. While halted new orders are rejected with 503 and emulated stop orders do not fire; cancels still work. With cancel_all every open order is cancelled as well. The state is saved to HALT_STATE_FILE when configured and survives restarts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Halt or resume trading",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "New state",
+                        "name": "halt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transport.HaltRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.HaltResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Applied but not saved",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/arbitrage": {
+            "get": {
+                "description": "List the price differences between exchanges found by the last scan of the ARB_SYMBOLS markets whose net spread, after taker fees and transfer cost, reaches ARB_MIN_SPREAD. Best net spread first; empty when the scanner is not configured.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "List arbitrage opportunities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ArbitrageOpportunity"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/v1/balance": {
             "get": {
                 "description": "Retrieve all asset balances of the account",
@@ -46,7 +173,7 @@ const docTemplate = `{
         },
         "/v1/book/{symbol}": {
             "get": {
-                "description": "Fetch the current order book for a trading symbol",
+                "description": "Fetch the current order book for a trading symbol. With aggregate=true the books of every configured exchange are merged into a domain.AggregatedBook whose levels carry each exchange's quantity.",
                 "produces": [
                     "application/json"
                 ],
@@ -57,10 +184,16 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Trading symbol",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
                         "name": "symbol",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Merge the book across all exchanges",
+                        "name": "aggregate",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -70,6 +203,129 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.OrderBook"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/candles/{symbol}": {
+            "get": {
+                "description": "Historical OHLCV bars for a trading symbol, oldest first. Ranges longer than the exchange allows per request are fetched in pages; at most 5000 bars can be requested at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "Get candles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bar width: 1m, 5m, 15m, 30m, 1h, 4h, 1d or 1w (default 1h)",
+                        "name": "resolution",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of range (RFC 3339 or Unix seconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC 3339 or Unix seconds), default now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Candle"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/exchanges": {
+            "get": {
+                "description": "List the exchanges this server is connected to and their accounts. Every other endpoint acts on the default exchange unless the request selects one with a /v1/{exchange} path prefix (e.g. /v1/wallex/balance) or the X-Exchange header. Order, balance, history and user-stream calls act for the account named by the X-Account header or the account query parameter, which may be omitted on exchanges with a single account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchanges"
+                ],
+                "summary": "List exchanges",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ExchangeList"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/markets": {
+            "get": {
+                "description": "List the exchange markets with tick size, step size and minimums",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "List markets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Market"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -80,8 +336,49 @@ const docTemplate = `{
             }
         },
         "/v1/orders": {
+            "get": {
+                "description": "List open orders on the configured exchange, optionally filtered by symbol",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "List open orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
+                        "name": "symbol",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.OrderResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Place a new order on the configured exchange",
+                "description": "Place a new order on the configured exchange. With route=smart a MARKET or LIMIT order is split across all exchanges by best price after taker fees, within each account's free balance; the response is a parent order (ID prefixed route-) whose Children are the orders placed on each exchange.",
                 "consumes": [
                     "application/json"
                 ],
@@ -101,6 +398,15 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/domain.OrderRequest"
                         }
+                    },
+                    {
+                        "enum": [
+                            "smart"
+                        ],
+                        "type": "string",
+                        "description": "Set to smart to route across exchanges",
+                        "name": "route",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -116,42 +422,272 @@ const docTemplate = `{
                             "$ref": "#/definitions/transport.ErrorResponse"
                         }
                     },
+                    "422": {
+                        "description": "Stopped by a pre-trade risk rule, named in rule",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/transport.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Trading is halted",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/v1/orders/{symbol}/{id}": {
+            },
             "delete": {
-                "description": "Cancel a placed order by symbol and ID",
+                "description": "Cancel every open order, optionally only on one symbol, and report the outcome per order",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "orders"
                 ],
-                "summary": "Cancel an existing order",
+                "summary": "Cancel all open orders",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Trading symbol",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
+                        "name": "symbol",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.CancelResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/orders/batch": {
+            "post": {
+                "description": "Place up to 100 orders concurrently and report the outcome of each. With all_or_nothing=true nothing is placed unless every order validates, and placed orders are cancelled again if any placement fails.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Create a batch of orders",
+                "parameters": [
+                    {
+                        "description": "Orders to place",
+                        "name": "orders",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.OrderRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Cancel placed orders if any order fails",
+                        "name": "all_or_nothing",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Every order was placed",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PlacementResult"
+                            }
+                        }
+                    },
+                    "207": {
+                        "description": "At least one order failed",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PlacementResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/orders/history": {
+            "get": {
+                "description": "Page through closed orders, optionally filtered by symbol and time range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Order history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
+                        "name": "symbol",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of range (RFC 3339 or Unix seconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC 3339 or Unix seconds)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.Page-domain_OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/orders/{symbol}/{id}": {
+            "get": {
+                "description": "Fetch the current state of an order by symbol and ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
                         "name": "symbol",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel a placed order by symbol and ID",
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Cancel an existing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "204": {
                         "description": "No Content"
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -160,20 +696,329 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Balance": {
+        },
+        "/v1/stream": {
+            "get": {
+                "description": "Server-sent events for the channels listed in ` + "`" + `channels` + "`" + `: ` + "`" + `book:\u003cSYMBOL\u003e` + "`" + `, ` + "`" + `ticker:\u003cSYMBOL\u003e` + "`" + `, ` + "`" + `trades:\u003cSYMBOL\u003e` + "`" + ` and ` + "`" + `user` + "`" + ` (private order, fill and balance updates). Each event's data is a transport.StreamFrame. Book channels start with a snapshot followed by updates whose Sequence increases by one. The same path accepts a websocket upgrade; websocket clients send transport.StreamCommand messages to subscribe and unsubscribe.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "stream"
+                ],
+                "summary": "Stream market and account updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated channels, e.g. book:BTC-IRT,user",
+                        "name": "channels",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.StreamFrame"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/ticker/{symbol}": {
+            "get": {
+                "description": "Last price, best bid/ask and 24h high, low, volume and change for a trading symbol",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "Get ticker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Ticker"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tickers": {
+            "get": {
+                "description": "Last price, best bid/ask and 24h statistics for every market",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "List tickers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Ticker"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trades": {
+            "get": {
+                "description": "Page through executed trades (fills), optionally filtered by symbol and time range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Trade history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
+                        "name": "symbol",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of range (RFC 3339 or Unix seconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC 3339 or Unix seconds)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (1-500, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.Page-domain_Trade"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trades/{symbol}/recent": {
+            "get": {
+                "description": "The latest trades on a market's public tape, newest first. Side is the taker's side.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "market"
+                ],
+                "summary": "Recent public trades",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Canonical symbol, e.g. BTC-IRT",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of trades (1-500, default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PublicTrade"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/transport.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.ArbitrageOpportunity": {
+            "type": "object",
+            "properties": {
+                "buyExchange": {
+                    "type": "string"
+                },
+                "buyPrice": {
+                    "type": "string"
+                },
+                "cost": {
+                    "type": "string"
+                },
+                "netProfit": {
+                    "type": "string"
+                },
+                "netSpread": {
+                    "type": "string"
+                },
+                "proceeds": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "string"
+                },
+                "seenAt": {
+                    "type": "string"
+                },
+                "sellExchange": {
+                    "type": "string"
+                },
+                "sellPrice": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "transferCost": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Balance": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "asset": {
+                    "type": "string"
+                },
+                "free": {
+                    "type": "string"
+                },
+                "locked": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CancelResult": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "canceled": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "orderID": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Candle": {
             "type": "object",
             "properties": {
-                "asset": {
+                "close": {
                     "type": "string"
                 },
-                "free": {
-                    "type": "number"
+                "high": {
+                    "type": "string"
                 },
-                "locked": {
-                    "type": "number"
+                "low": {
+                    "type": "string"
+                },
+                "open": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "string"
                 }
             }
         },
@@ -181,10 +1026,61 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "quantity": {
-                    "type": "number"
+                    "type": "string"
+                }
+            }
+        },
+        "domain.HaltState": {
+            "type": "object",
+            "properties": {
+                "by": {
+                    "type": "string"
+                },
+                "halted": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Liquidity": {
+            "type": "string",
+            "enum": [
+                "MAKER",
+                "TAKER"
+            ],
+            "x-enum-varnames": [
+                "LiquidityMaker",
+                "LiquidityTaker"
+            ]
+        },
+        "domain.Market": {
+            "type": "object",
+            "properties": {
+                "minNotional": {
+                    "type": "string"
+                },
+                "minQuantity": {
+                    "type": "string"
+                },
+                "stepSize": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tickSize": {
+                    "type": "string"
+                },
+                "tradable": {
+                    "type": "boolean"
                 }
             }
         },
@@ -214,21 +1110,33 @@ const docTemplate = `{
                 "clientID": {
                     "type": "string"
                 },
+                "postOnly": {
+                    "type": "boolean"
+                },
                 "price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "quantity": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "side": {
                     "$ref": "#/definitions/domain.OrderSide"
                 },
+                "stopLimitPrice": {
+                    "type": "string"
+                },
                 "symbol": {
                     "type": "string"
                 },
+                "timeInForce": {
+                    "$ref": "#/definitions/domain.TimeInForce"
+                },
                 "timestamp": {
                     "type": "string"
                 },
+                "triggerPrice": {
+                    "type": "string"
+                },
                 "type": {
                     "$ref": "#/definitions/domain.OrderType"
                 }
@@ -237,20 +1145,42 @@ const docTemplate = `{
         "domain.OrderResponse": {
             "type": "object",
             "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "avgPrice": {
+                    "type": "string"
+                },
+                "children": {
+                    "description": "Children holds the orders placed on behalf of this one, such as the\nlegs of an emulated stop or OCO order or the per-exchange parts of a\nrouted order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.OrderResponse"
+                    }
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "filledQuantity": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
                 "price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "quantity": {
-                    "type": "number"
+                    "type": "string"
+                },
+                "rawStatus": {
+                    "type": "string"
                 },
                 "side": {
                     "$ref": "#/definitions/domain.OrderSide"
                 },
                 "status": {
-                    "type": "string"
+                    "$ref": "#/definitions/domain.OrderStatus"
                 },
                 "symbol": {
                     "type": "string"
@@ -274,22 +1204,295 @@ const docTemplate = `{
                 "SideSell"
             ]
         },
+        "domain.OrderStatus": {
+            "type": "string",
+            "enum": [
+                "NEW",
+                "PARTIALLY_FILLED",
+                "FILLED",
+                "CANCELED",
+                "REJECTED",
+                "EXPIRED"
+            ],
+            "x-enum-varnames": [
+                "StatusNew",
+                "StatusPartiallyFilled",
+                "StatusFilled",
+                "StatusCanceled",
+                "StatusRejected",
+                "StatusExpired"
+            ]
+        },
         "domain.OrderType": {
             "type": "string",
             "enum": [
                 "MARKET",
-                "LIMIT"
+                "LIMIT",
+                "STOP_MARKET",
+                "STOP_LIMIT",
+                "OCO"
             ],
             "x-enum-varnames": [
                 "TypeMarket",
-                "TypeLimit"
+                "TypeLimit",
+                "TypeStopMarket",
+                "TypeStopLimit",
+                "TypeOCO"
+            ]
+        },
+        "domain.PlacementResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "order": {
+                    "$ref": "#/definitions/domain.OrderResponse"
+                },
+                "rolledBack": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.PublicTrade": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "string"
+                },
+                "side": {
+                    "$ref": "#/definitions/domain.OrderSide"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Ticker": {
+            "type": "object",
+            "properties": {
+                "ask": {
+                    "type": "string"
+                },
+                "bid": {
+                    "type": "string"
+                },
+                "changePercent": {
+                    "type": "string"
+                },
+                "high": {
+                    "type": "string"
+                },
+                "last": {
+                    "type": "string"
+                },
+                "low": {
+                    "type": "string"
+                },
+                "quoteVolume": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TimeInForce": {
+            "type": "string",
+            "enum": [
+                "GTC",
+                "IOC",
+                "FOK"
+            ],
+            "x-enum-varnames": [
+                "TimeInForceGTC",
+                "TimeInForceIOC",
+                "TimeInForceFOK"
             ]
         },
+        "domain.Trade": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "fee": {
+                    "type": "string"
+                },
+                "feeAsset": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "liquidity": {
+                    "$ref": "#/definitions/domain.Liquidity"
+                },
+                "orderID": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "string"
+                },
+                "side": {
+                    "$ref": "#/definitions/domain.OrderSide"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
         "transport.ErrorResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "transport.ExchangeList": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "default": {
+                    "type": "string",
+                    "example": "bitpin"
+                },
+                "exchanges": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "bitpin",
+                        "wallex"
+                    ]
+                }
+            }
+        },
+        "transport.HaltRequest": {
+            "type": "object",
+            "properties": {
+                "cancel_all": {
+                    "type": "boolean"
+                },
+                "halted": {
+                    "type": "boolean"
+                },
+                "operator": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "runaway strategy"
+                }
+            }
+        },
+        "transport.HaltResponse": {
+            "type": "object",
+            "properties": {
+                "canceled": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CancelResult"
+                    }
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.HaltState"
+                }
+            }
+        },
+        "transport.Page-domain_OrderResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.OrderResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_offset": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                }
+            }
+        },
+        "transport.Page-domain_Trade": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Trade"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_offset": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                }
+            }
+        },
+        "transport.StreamFrame": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "data": {},
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
                 }
             }
         }
@@ -7,22 +7,32 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"trade/internal/adapters/udf"
 	"trade/internal/domain"
 	"trade/internal/ports"
 )
 
 type BitpinAdapter struct {
-	client *Client
-	log    ports.LoggerPort
+	client  *Client
+	symbols domain.SymbolTranslator
+	log     ports.LoggerPort
 }
 
 func NewAdapter(apiKey, apiSecret, baseURL string, log ports.LoggerPort) *BitpinAdapter {
 	c := NewClient(apiKey, apiSecret, baseURL, log)
-	return &BitpinAdapter{client: c, log: log}
+	return &BitpinAdapter{client: c, symbols: symbolTranslator{}, log: log}
+}
+
+// Capabilities reports Bitpin's native stop-limit and OCO order types.
+// Stop-market orders, IOC/FOK and post-only are emulated by the
+// application layer.
+func (b *BitpinAdapter) Capabilities() domain.Capabilities {
+	return domain.Capabilities{StopLimit: true, OCO: true}
 }
 
 func (b *BitpinAdapter) CreateOrder(ctx context.Context, req domain.OrderRequest) (domain.OrderResponse, error) {
@@ -30,13 +40,24 @@ func (b *BitpinAdapter) CreateOrder(ctx context.Context, req domain.OrderRequest
 	url := fmt.Sprintf("%s/api/v1/odr/orders/", b.client.baseURL)
 
 	payload := map[string]interface{}{
-		"symbol":      req.Symbol,
+		"symbol":      b.symbols.ToExchange(req.Symbol),
 		"type":        strings.ToLower(string(req.Type)),
 		"side":        strings.ToLower(string(req.Side)),
-		"base_amount": fmt.Sprintf("%f", req.Quantity),
+		"base_amount": req.Quantity.String(),
 	}
 	if req.Price != nil {
-		payload["price"] = fmt.Sprintf("%f", *req.Price)
+		payload["price"] = req.Price.String()
+	}
+	if req.TriggerPrice != nil {
+		payload["stop_price"] = req.TriggerPrice.String()
+	}
+	if req.Type == domain.TypeOCO {
+		// Bitpin's OCO stop leg is always a limit order.
+		stopLimit := req.TriggerPrice
+		if req.StopLimitPrice != nil {
+			stopLimit = req.StopLimitPrice
+		}
+		payload["oco_target_price"] = stopLimit.String()
 	}
 	if req.ClientID != nil {
 		payload["identifier"] = *req.ClientID
@@ -59,37 +80,15 @@ func (b *BitpinAdapter) CreateOrder(ctx context.Context, req domain.OrderRequest
 		return domain.OrderResponse{}, err
 	}
 
-	var r struct {
-		ID                int64  `json:"id"`
-		Symbol            string `json:"symbol"`
-		Side, Type        string
-		Price             string `json:"price"`
-		DealedBaseAmount  string `json:"dealed_base_amount"`
-		DealedQuoteAmount string `json:"dealed_quote_amount"`
-		State             string `json:"state"`
-		CreatedAt         string `json:"created_at"`
-	}
+	var r order
 	json.NewDecoder(resp.Body).Decode(&r)
 
-	qty, _ := strconv.ParseFloat(r.DealedBaseAmount, 64)
-	priceF, _ := strconv.ParseFloat(r.Price, 64)
-	ts, _ := time.Parse(time.RFC3339, r.CreatedAt)
-
-	result := domain.OrderResponse{
-		ID:        strconv.FormatInt(r.ID, 10),
-		Symbol:    r.Symbol,
-		Side:      domain.OrderSide(strings.ToUpper(r.Side)),
-		Type:      domain.OrderType(strings.ToUpper(r.Type)),
-		Quantity:  qty,
-		Price:     priceF,
-		Status:    r.State,
-		Timestamp: ts,
-	}
+	result := r.toDomain(b.symbols, req.Symbol)
 	b.log.Info(ctx, "CreateOrder succeeded", ports.Fields{"orderID": result.ID})
 	return result, nil
 }
 
-func (b *BitpinAdapter) CancelOrder(ctx context.Context, symbol, orderID string) error {
+func (b *BitpinAdapter) CancelOrder(ctx context.Context, symbol domain.Symbol, orderID string) error {
 	b.log.Info(ctx, "CancelOrder start", ports.Fields{"symbol": symbol, "orderID": orderID})
 	url := fmt.Sprintf("%s/api/v1/odr/orders/%s/", b.client.baseURL, orderID)
 	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
@@ -110,6 +109,131 @@ func (b *BitpinAdapter) CancelOrder(ctx context.Context, symbol, orderID string)
 	return nil
 }
 
+// CancelAll uses Bitpin's bulk cancel endpoint, which reports the orders
+// it cancelled.
+func (b *BitpinAdapter) CancelAll(ctx context.Context, symbol *domain.Symbol) ([]domain.CancelResult, error) {
+	b.log.Info(ctx, "CancelAll start", ports.Fields{"symbol": symbol})
+	url := fmt.Sprintf("%s/api/v1/odr/orders/cancel-all/", b.client.baseURL)
+
+	payload := map[string]interface{}{}
+	var fallback domain.Symbol
+	if symbol != nil {
+		payload["symbol"] = b.symbols.ToExchange(*symbol)
+		fallback = *symbol
+	}
+	bodyBytes, _ := json.Marshal(payload)
+	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(ctx, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("status %d: %s", resp.StatusCode, data)
+		b.log.Error(ctx, "CancelAll failed", ports.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	var canceled []order
+	if err := json.NewDecoder(resp.Body).Decode(&canceled); err != nil {
+		b.log.Error(ctx, "CancelAll decode error", ports.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	results := make([]domain.CancelResult, 0, len(canceled))
+	for _, o := range canceled {
+		d := o.toDomain(b.symbols, fallback)
+		results = append(results, domain.CancelResult{OrderID: d.ID, Symbol: d.Symbol, Canceled: true})
+	}
+	b.log.Info(ctx, "CancelAll succeeded", ports.Fields{"count": len(results)})
+	return results, nil
+}
+
+func (b *BitpinAdapter) GetOrder(ctx context.Context, symbol domain.Symbol, orderID string) (domain.OrderResponse, error) {
+	var r order
+	endpoint := fmt.Sprintf("%s/api/v1/odr/orders/%s/", b.client.baseURL, url.PathEscape(orderID))
+	if err := b.getJSON(ctx, "GetOrder", endpoint, &r); err != nil {
+		return domain.OrderResponse{}, err
+	}
+	return r.toDomain(b.symbols, symbol), nil
+}
+
+func (b *BitpinAdapter) ListOpenOrders(ctx context.Context, symbol *domain.Symbol) ([]domain.OrderResponse, error) {
+	q := url.Values{"state": {"active"}}
+	var fallback domain.Symbol
+	if symbol != nil {
+		q.Set("symbol", b.symbols.ToExchange(*symbol))
+		fallback = *symbol
+	}
+	var r []order
+	endpoint := fmt.Sprintf("%s/api/v1/odr/orders/?%s", b.client.baseURL, q.Encode())
+	if err := b.getJSON(ctx, "ListOpenOrders", endpoint, &r); err != nil {
+		return nil, err
+	}
+
+	orders := make([]domain.OrderResponse, 0, len(r))
+	for _, o := range r {
+		orders = append(orders, o.toDomain(b.symbols, fallback))
+	}
+	return orders, nil
+}
+
+func (b *BitpinAdapter) GetOrderHistory(ctx context.Context, filter domain.HistoryFilter) ([]domain.OrderResponse, error) {
+	q := b.historyQuery(filter)
+	q.Set("state", "closed")
+	var r []order
+	endpoint := fmt.Sprintf("%s/api/v1/odr/orders/?%s", b.client.baseURL, q.Encode())
+	if err := b.getJSON(ctx, "GetOrderHistory", endpoint, &r); err != nil {
+		return nil, err
+	}
+
+	orders := make([]domain.OrderResponse, 0, len(r))
+	for _, o := range r {
+		orders = append(orders, o.toDomain(b.symbols, domain.Symbol{}))
+	}
+	return orders, nil
+}
+
+func (b *BitpinAdapter) GetTrades(ctx context.Context, filter domain.HistoryFilter) ([]domain.Trade, error) {
+	var r []fill
+	endpoint := fmt.Sprintf("%s/api/v1/odr/fills/?%s", b.client.baseURL, b.historyQuery(filter).Encode())
+	if err := b.getJSON(ctx, "GetTrades", endpoint, &r); err != nil {
+		return nil, err
+	}
+
+	trades := make([]domain.Trade, 0, len(r))
+	for _, f := range r {
+		trades = append(trades, f.toDomain(b.symbols, domain.Symbol{}))
+	}
+	return trades, nil
+}
+
+// historyQuery maps a history filter onto Bitpin's offset/limit and
+// start/end query parameters.
+func (b *BitpinAdapter) historyQuery(filter domain.HistoryFilter) url.Values {
+	q := url.Values{}
+	if filter.Symbol != nil {
+		q.Set("symbol", b.symbols.ToExchange(*filter.Symbol))
+	}
+	if filter.Limit > 0 {
+		q.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		q.Set("offset", strconv.Itoa(filter.Offset))
+	}
+	if !filter.From.IsZero() {
+		q.Set("start", filter.From.UTC().Format(time.RFC3339))
+	}
+	if !filter.To.IsZero() {
+		q.Set("end", filter.To.UTC().Format(time.RFC3339))
+	}
+	return q
+}
+
 func (b *BitpinAdapter) GetBalance(ctx context.Context) ([]domain.Balance, error) {
 	url := fmt.Sprintf("%s/api/v1/wlt/wallets/", b.client.baseURL)
 	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -128,11 +252,7 @@ func (b *BitpinAdapter) GetBalance(ctx context.Context) ([]domain.Balance, error
 		return nil, err
 	}
 
-	var wallets []struct {
-		Asset   string `json:"asset"`
-		Balance string `json:"balance"`
-		Frozen  string `json:"frozen"`
-	}
+	var wallets []wallet
 	if err := json.NewDecoder(resp.Body).Decode(&wallets); err != nil {
 		b.log.Error(ctx, "GetBalance decode error", ports.Fields{"error": err.Error()})
 		return nil, err
@@ -140,10 +260,7 @@ func (b *BitpinAdapter) GetBalance(ctx context.Context) ([]domain.Balance, error
 
 	balances := make([]domain.Balance, 0, len(wallets))
 	for _, w := range wallets {
-		total, _ := strconv.ParseFloat(w.Balance, 64)
-		frozen, _ := strconv.ParseFloat(w.Frozen, 64)
-		free := total - frozen
-		balances = append(balances, domain.Balance{Asset: w.Asset, Free: free, Locked: frozen})
+		balances = append(balances, w.toDomain())
 	}
 
 	b.log.Info(ctx, "GetBalance succeeded", ports.Fields{
@@ -152,9 +269,9 @@ func (b *BitpinAdapter) GetBalance(ctx context.Context) ([]domain.Balance, error
 	return balances, nil
 }
 
-func (b *BitpinAdapter) GetOrderBook(ctx context.Context, symbol string) (domain.OrderBook, error) {
+func (b *BitpinAdapter) GetOrderBook(ctx context.Context, symbol domain.Symbol) (domain.OrderBook, error) {
 	b.log.Info(ctx, "GetOrderBook start", ports.Fields{"symbol": symbol})
-	url := fmt.Sprintf("%s/api/v1/mth/orderbook/%s/", b.client.baseURL, symbol)
+	url := fmt.Sprintf("%s/api/v1/mth/orderbook/%s/", b.client.baseURL, b.symbols.ToExchange(symbol))
 	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
 	resp, err := b.client.Do(ctx, httpReq)
@@ -178,14 +295,14 @@ func (b *BitpinAdapter) GetOrderBook(ctx context.Context, symbol string) (domain
 
 	asks := make([]domain.DepthLevel, len(r.Asks))
 	for i, lvl := range r.Asks {
-		price, _ := strconv.ParseFloat(lvl[0], 64)
-		qty, _ := strconv.ParseFloat(lvl[1], 64)
+		price, _ := domain.ParseDecimal(lvl[0])
+		qty, _ := domain.ParseDecimal(lvl[1])
 		asks[i] = domain.DepthLevel{Price: price, Quantity: qty}
 	}
 	bids := make([]domain.DepthLevel, len(r.Bids))
 	for i, lvl := range r.Bids {
-		price, _ := strconv.ParseFloat(lvl[0], 64)
-		qty, _ := strconv.ParseFloat(lvl[1], 64)
+		price, _ := domain.ParseDecimal(lvl[0])
+		qty, _ := domain.ParseDecimal(lvl[1])
 		bids[i] = domain.DepthLevel{Price: price, Quantity: qty}
 	}
 
@@ -193,3 +310,165 @@ func (b *BitpinAdapter) GetOrderBook(ctx context.Context, symbol string) (domain
 	b.log.Info(ctx, "GetOrderBook succeeded", ports.Fields{"bids": len(bids), "asks": len(asks)})
 	return book, nil
 }
+
+// GetRecentTrades reads Bitpin's public match list, which has no size
+// parameter; it is truncated to limit here.
+func (b *BitpinAdapter) GetRecentTrades(ctx context.Context, symbol domain.Symbol, limit int) ([]domain.PublicTrade, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/mth/matches/%s/", b.client.baseURL, b.symbols.ToExchange(symbol))
+	var r []match
+	if err := b.getJSON(ctx, "GetRecentTrades", endpoint, &r); err != nil {
+		return nil, err
+	}
+	if len(r) > limit {
+		r = r[:limit]
+	}
+
+	trades := make([]domain.PublicTrade, len(r))
+	for i, m := range r {
+		trades[i] = m.toDomain(symbol)
+	}
+	return trades, nil
+}
+
+// GetMarkets maps Bitpin's market list, which publishes only price and
+// amount precisions. Bitpin's minimum order size and value are not part of
+// any market payload, so MinQuantity and MinNotional stay zero and orders
+// below them are rejected by the exchange itself.
+func (b *BitpinAdapter) GetMarkets(ctx context.Context) ([]domain.Market, error) {
+	url := fmt.Sprintf("%s/api/v1/mkt/markets/", b.client.baseURL)
+	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	resp, err := b.client.Do(ctx, httpReq)
+	if err != nil {
+		b.log.Error(ctx, "GetMarkets request error", ports.Fields{"error": err.Error()})
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("status %d: %s", resp.StatusCode, data)
+		b.log.Error(ctx, "GetMarkets failed", ports.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	var r []struct {
+		Symbol               string `json:"symbol"`
+		Base                 string `json:"base"`
+		Quote                string `json:"quote"`
+		Tradable             bool   `json:"tradable"`
+		PricePrecision       int32  `json:"price_precision"`
+		BaseAmountPrecision  int32  `json:"base_amount_precision"`
+		QuoteAmountPrecision int32  `json:"quote_amount_precision"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		b.log.Error(ctx, "GetMarkets decode error", ports.Fields{"error": err.Error()})
+		return nil, err
+	}
+
+	markets := make([]domain.Market, 0, len(r))
+	for _, m := range r {
+		markets = append(markets, domain.Market{
+			Symbol:   domain.NewSymbol(m.Base, m.Quote),
+			TickSize: domain.Precision(m.PricePrecision),
+			StepSize: domain.Precision(m.BaseAmountPrecision),
+			Tradable: m.Tradable,
+		})
+	}
+
+	b.log.Info(ctx, "GetMarkets succeeded", ports.Fields{"count": len(markets)})
+	return markets, nil
+}
+
+// GetTicker has no per-market endpoint on Bitpin, so it picks the market
+// out of the full ticker list.
+func (b *BitpinAdapter) GetTicker(ctx context.Context, symbol domain.Symbol) (domain.Ticker, error) {
+	tickers, err := b.GetTickers(ctx)
+	if err != nil {
+		return domain.Ticker{}, err
+	}
+	for _, t := range tickers {
+		if t.Symbol == symbol {
+			return t, nil
+		}
+	}
+	return domain.Ticker{}, fmt.Errorf("no ticker for %s", symbol)
+}
+
+func (b *BitpinAdapter) GetTickers(ctx context.Context) ([]domain.Ticker, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/mkt/tickers/", b.client.baseURL)
+	var r []ticker
+	if err := b.getJSON(ctx, "GetTickers", endpoint, &r); err != nil {
+		return nil, err
+	}
+
+	tickers := make([]domain.Ticker, 0, len(r))
+	for _, t := range r {
+		symbol, err := b.symbols.FromExchange(t.Symbol)
+		if err != nil {
+			continue
+		}
+		tickers = append(tickers, t.toDomain(symbol))
+	}
+	return tickers, nil
+}
+
+// maxCandlesPerRequest is the most bars Bitpin returns for one history
+// request; longer ranges are fetched window by window.
+const maxCandlesPerRequest = 500
+
+func (b *BitpinAdapter) GetCandles(ctx context.Context, symbol domain.Symbol, resolution domain.Resolution, from, to time.Time) ([]domain.Candle, error) {
+	res, ok := udf.Resolutions[resolution]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resolution %q", resolution)
+	}
+
+	var candles []domain.Candle
+	for _, win := range resolution.Windows(from, to, maxCandlesPerRequest) {
+		q := url.Values{}
+		q.Set("symbol", b.symbols.ToExchange(symbol))
+		q.Set("resolution", res)
+		q.Set("from", strconv.FormatInt(win.From.Unix(), 10))
+		q.Set("to", strconv.FormatInt(win.To.Unix(), 10))
+		endpoint := fmt.Sprintf("%s/api/v1/mkt/tv/history/?%s", b.client.baseURL, q.Encode())
+
+		var h udf.History
+		if err := b.getJSON(ctx, "GetCandles", endpoint, &h); err != nil {
+			return nil, err
+		}
+		bars, err := h.Candles(win.From, win.To)
+		if err != nil {
+			b.log.Error(ctx, "GetCandles failed", ports.Fields{"symbol": symbol, "error": err.Error()})
+			return nil, err
+		}
+		candles = append(candles, bars...)
+	}
+	return candles, nil
+}
+
+// getJSON performs an authenticated GET and decodes a 200 response into out,
+// logging the outcome under op the same way the other adapter calls do.
+func (b *BitpinAdapter) getJSON(ctx context.Context, op, endpoint string, out interface{}) error {
+	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+
+	resp, err := b.client.Do(ctx, httpReq)
+	if err != nil {
+		b.log.Error(ctx, op+" request error", ports.Fields{"error": err.Error()})
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("status %d: %s", resp.StatusCode, data)
+		b.log.Error(ctx, op+" failed", ports.Fields{"error": err.Error()})
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		b.log.Error(ctx, op+" decode error", ports.Fields{"error": err.Error()})
+		return err
+	}
+	b.log.Info(ctx, op+" succeeded", nil)
+	return nil
+}
@@ -0,0 +1,128 @@
+package bitpin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+
+	"trade/internal/adapters/wsconn"
+	"trade/internal/ports"
+)
+
+// Bitpin streams over Centrifugo's JSON protocol: every command carries an
+// id, the server pushes publications per channel, and an empty object is a
+// ping that must be answered with one. Several replies may share a frame,
+// separated by newlines.
+type cfCommand struct {
+	ID          uint32     `json:"id"`
+	Connect     *cfConnect `json:"connect,omitempty"`
+	Subscribe   *cfChannel `json:"subscribe,omitempty"`
+	Unsubscribe *cfChannel `json:"unsubscribe,omitempty"`
+}
+
+type cfConnect struct {
+	Token string `json:"token,omitempty"`
+}
+
+type cfChannel struct {
+	Channel string `json:"channel"`
+}
+
+type cfReply struct {
+	ID    uint32 `json:"id"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Push *struct {
+		Channel string `json:"channel"`
+		Pub     *struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"pub"`
+	} `json:"push"`
+}
+
+// centrifugo is one Centrifugo connection. channels is asked for the full
+// subscription list after every (re)connect; token, when set, supplies the
+// connection token for private channels.
+type centrifugo struct {
+	conn   *wsconn.Conn
+	nextID atomic.Uint32
+	log    ports.LoggerPort
+}
+
+func newCentrifugo(ctx context.Context, name, url string, token func(ctx context.Context) string, channels func() []string, onPub func(channel string, data json.RawMessage), log ports.LoggerPort) *centrifugo {
+	cf := &centrifugo{log: log}
+	cf.conn = wsconn.Dial(ctx, name, wsconn.Options{
+		URL: url,
+		OnConnect: func(ctx context.Context) error {
+			connect := &cfConnect{}
+			if token != nil {
+				connect.Token = token(ctx)
+			}
+			if err := cf.send(cfCommand{Connect: connect}); err != nil {
+				return err
+			}
+			for _, ch := range channels() {
+				if err := cf.subscribe(ch); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		OnMessage: func(data []byte) {
+			for _, line := range bytes.Split(data, []byte("\n")) {
+				cf.handle(ctx, line, onPub)
+			}
+		},
+	}, log)
+	return cf
+}
+
+func (cf *centrifugo) handle(ctx context.Context, line []byte, onPub func(string, json.RawMessage)) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	if bytes.Equal(line, []byte("{}")) {
+		_ = cf.conn.Send([]byte("{}"))
+		return
+	}
+	var r cfReply
+	if err := json.Unmarshal(line, &r); err != nil {
+		cf.log.Error(ctx, "bitpin stream decode error", ports.Fields{"error": err.Error()})
+		return
+	}
+	if r.Error != nil {
+		cf.log.Error(ctx, "bitpin stream command failed", ports.Fields{"id": r.ID, "code": r.Error.Code, "error": r.Error.Message})
+		return
+	}
+	if r.Push != nil && r.Push.Pub != nil {
+		onPub(r.Push.Channel, r.Push.Pub.Data)
+	}
+}
+
+func (cf *centrifugo) send(cmd cfCommand) error {
+	cmd.ID = cf.nextID.Add(1)
+	return cf.conn.SendJSON(cmd)
+}
+
+// subscribe and unsubscribe are no-ops while disconnected; the
+// subscription list is replayed on reconnect.
+func (cf *centrifugo) subscribe(channel string) error {
+	err := cf.send(cfCommand{Subscribe: &cfChannel{Channel: channel}})
+	if errors.Is(err, wsconn.ErrNotConnected) {
+		return nil
+	}
+	return err
+}
+
+func (cf *centrifugo) unsubscribe(channel string) error {
+	err := cf.send(cfCommand{Unsubscribe: &cfChannel{Channel: channel}})
+	if errors.Is(err, wsconn.ErrNotConnected) {
+		return nil
+	}
+	return err
+}
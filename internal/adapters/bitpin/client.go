@@ -161,11 +161,22 @@ func (c *Client) autoRefreshLoop(ctx context.Context) {
 	}
 }
 
-func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
-	if time.Until(c.expiry) < 30*time.Second {
+// AccessToken returns the current access token, refreshing it first when
+// it is about to expire. The private websocket authenticates with it too.
+func (c *Client) AccessToken(ctx context.Context) string {
+	c.mu.Lock()
+	expiring := time.Until(c.expiry) < 30*time.Second
+	c.mu.Unlock()
+	if expiring {
 		_ = c.refreshToken(ctx)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken(ctx))
 	return c.httpClient.Do(req)
 }
@@ -0,0 +1,294 @@
+package bitpin
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"trade/internal/adapters/wsconn"
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// streamBuffer is the number of undelivered values a subscriber may have
+// queued before it counts as lagging.
+const streamBuffer = 256
+
+const (
+	bookChannel   = "orderbook:"
+	tradesChannel = "matches:"
+	tickerChannel = "ticker:"
+)
+
+// bookState is the stream's copy of one symbol's book. Bitpin publishes
+// diffs only, so the book is seeded from REST and re-seeded whenever a
+// publication is missed.
+type bookState struct {
+	book domain.OrderBook
+	// seq is the Sequence of the last update delivered to subscribers.
+	seq uint64
+	// exchSeq is the last Bitpin sequence applied, zero until the first
+	// diff after a snapshot.
+	exchSeq uint64
+	// While syncing, diffs are queued in pending until the REST snapshot
+	// arrives. gen identifies the resync in flight.
+	syncing bool
+	pending []bookDiff
+	gen     uint64
+}
+
+// MarketStream implements domain.MarketStreamPort over Bitpin's public
+// websocket.
+type MarketStream struct {
+	rest *BitpinAdapter
+	ctx  context.Context
+	cf   *centrifugo
+	log  ports.LoggerPort
+
+	mu      sync.Mutex
+	books   *wsconn.Topics[domain.BookUpdate]
+	trades  *wsconn.Topics[domain.PublicTrade]
+	tickers *wsconn.Topics[domain.Ticker]
+	state   map[domain.Symbol]*bookState
+}
+
+// NewMarketStream connects to wsURL and keeps the connection up until ctx
+// is done. rest is used to seed and resynchronise order books.
+func NewMarketStream(ctx context.Context, wsURL string, rest *BitpinAdapter, log ports.LoggerPort) *MarketStream {
+	s := &MarketStream{
+		rest:    rest,
+		ctx:     ctx,
+		log:     log,
+		books:   wsconn.NewTopics[domain.BookUpdate](streamBuffer),
+		trades:  wsconn.NewTopics[domain.PublicTrade](streamBuffer),
+		tickers: wsconn.NewTopics[domain.Ticker](streamBuffer),
+		state:   make(map[domain.Symbol]*bookState),
+	}
+	s.cf = newCentrifugo(ctx, "bitpin", wsURL, nil, s.resubscribe, s.onPublication, log)
+	return s
+}
+
+func (s *MarketStream) SubscribeBook(ctx context.Context, symbol domain.Symbol) (<-chan domain.BookUpdate, error) {
+	topic := bookChannel + s.rest.symbols.ToExchange(symbol)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, first := s.books.Add(topic)
+	if first {
+		st := &bookState{}
+		s.state[symbol] = st
+		if err := s.cf.subscribe(topic); err != nil {
+			s.books.Remove(topic, ch)
+			delete(s.state, symbol)
+			return nil, err
+		}
+		s.startResync(symbol, st)
+	} else if st := s.state[symbol]; !st.syncing {
+		wsconn.Offer(ch, s.snapshot(symbol, st))
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.removeBook(symbol, topic, ch)
+	}()
+	return ch, nil
+}
+
+func (s *MarketStream) SubscribeTrades(ctx context.Context, symbol domain.Symbol) (<-chan domain.PublicTrade, error) {
+	return subscribe(ctx, s, s.trades, tradesChannel+s.rest.symbols.ToExchange(symbol))
+}
+
+func (s *MarketStream) SubscribeTicker(ctx context.Context, symbol domain.Symbol) (<-chan domain.Ticker, error) {
+	return subscribe(ctx, s, s.tickers, tickerChannel+s.rest.symbols.ToExchange(symbol))
+}
+
+// subscribe registers a trade or ticker subscriber; these streams carry no
+// state, so a lagging subscriber just misses values.
+func subscribe[T any](ctx context.Context, s *MarketStream, topics *wsconn.Topics[T], topic string) (<-chan T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, first := topics.Add(topic)
+	if first {
+		if err := s.cf.subscribe(topic); err != nil {
+			topics.Remove(topic, ch)
+			return nil, err
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if topics.Remove(topic, ch) {
+			_ = s.cf.unsubscribe(topic)
+		}
+	}()
+	return ch, nil
+}
+
+// resubscribe runs after every (re)connect. Diffs may have been missed
+// while disconnected, so every book is resynchronised.
+func (s *MarketStream) resubscribe() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for symbol, st := range s.state {
+		s.startResync(symbol, st)
+	}
+	var out []string
+	out = append(out, s.books.Names()...)
+	out = append(out, s.trades.Names()...)
+	out = append(out, s.tickers.Names()...)
+	return out
+}
+
+func (s *MarketStream) onPublication(channel string, data json.RawMessage) {
+	prefix, native, ok := strings.Cut(channel, ":")
+	if !ok {
+		return
+	}
+	symbol, err := s.rest.symbols.FromExchange(native)
+	if err != nil {
+		return
+	}
+
+	var decodeErr error
+	switch prefix + ":" {
+	case bookChannel:
+		var d bookDiff
+		if decodeErr = json.Unmarshal(data, &d); decodeErr == nil {
+			s.onBookDiff(symbol, channel, d)
+		}
+	case tradesChannel:
+		var ms []match
+		if decodeErr = json.Unmarshal(data, &ms); decodeErr == nil {
+			s.mu.Lock()
+			for _, m := range ms {
+				s.trades.Publish(channel, m.toDomain(symbol))
+			}
+			s.mu.Unlock()
+		}
+	case tickerChannel:
+		var t ticker
+		if decodeErr = json.Unmarshal(data, &t); decodeErr == nil {
+			s.mu.Lock()
+			s.tickers.Publish(channel, t.toDomain(symbol))
+			s.mu.Unlock()
+		}
+	}
+	if decodeErr != nil {
+		s.log.Error(s.ctx, "bitpin stream decode error", ports.Fields{"channel": channel, "error": decodeErr.Error()})
+	}
+}
+
+func (s *MarketStream) onBookDiff(symbol domain.Symbol, topic string, d bookDiff) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.state[symbol]
+	switch {
+	case !ok:
+	case st.syncing:
+		st.pending = append(st.pending, d)
+	case st.exchSeq != 0 && d.Seq <= st.exchSeq:
+		// Duplicate of a diff already applied.
+	case st.exchSeq != 0 && d.Seq != st.exchSeq+1:
+		s.log.Error(s.ctx, "bitpin book gap, resyncing", ports.Fields{"symbol": symbol, "expected": st.exchSeq + 1, "got": d.Seq})
+		s.startResync(symbol, st)
+		st.pending = append(st.pending, d)
+	default:
+		s.applyDiff(symbol, topic, st, d)
+	}
+}
+
+func (s *MarketStream) applyDiff(symbol domain.Symbol, topic string, st *bookState, d bookDiff) {
+	st.exchSeq = d.Seq
+	st.seq++
+	u := domain.BookUpdate{
+		Symbol:   symbol,
+		Sequence: st.seq,
+		Bids:     depthLevels(d.Bids),
+		Asks:     depthLevels(d.Asks),
+		Time:     time.Now(),
+	}
+	st.book.Apply(u)
+	u.Checksum = st.book.Checksum()
+	s.publishBook(symbol, topic, u)
+}
+
+// startResync queues diffs from now on and fetches a fresh snapshot in the
+// background. Callers hold s.mu.
+func (s *MarketStream) startResync(symbol domain.Symbol, st *bookState) {
+	st.syncing = true
+	st.pending = nil
+	st.gen++
+	go s.resync(symbol, st.gen)
+}
+
+func (s *MarketStream) resync(symbol domain.Symbol, gen uint64) {
+	topic := bookChannel + s.rest.symbols.ToExchange(symbol)
+	backoff := time.Second
+	for {
+		snap, err := s.rest.GetOrderBook(s.ctx, symbol)
+		if err == nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			st, ok := s.state[symbol]
+			if !ok || st.gen != gen {
+				return
+			}
+			st.seq++
+			u := domain.BookUpdate{Symbol: symbol, Sequence: st.seq, Snapshot: true, Bids: snap.Bids, Asks: snap.Asks, Time: time.Now()}
+			st.book.Apply(u)
+			s.publishBook(symbol, topic, u)
+
+			// Levels carry absolute quantities, so replaying diffs that
+			// the snapshot already includes is harmless.
+			pending := st.pending
+			st.syncing, st.pending, st.exchSeq = false, nil, 0
+			for _, d := range pending {
+				if st.exchSeq != 0 && d.Seq != st.exchSeq+1 {
+					s.startResync(symbol, st)
+					return
+				}
+				s.applyDiff(symbol, topic, st, d)
+			}
+			return
+		}
+
+		s.log.Error(s.ctx, "bitpin book snapshot failed", ports.Fields{"symbol": symbol, "error": err.Error()})
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// publishBook delivers u and drops subscribers that cannot keep up, since
+// a book with a missing update is wrong. Callers hold s.mu.
+func (s *MarketStream) publishBook(symbol domain.Symbol, topic string, u domain.BookUpdate) {
+	for _, ch := range s.books.Publish(topic, u) {
+		s.log.Error(s.ctx, "book subscriber lagging, closing", ports.Fields{"symbol": symbol})
+		s.removeBook(symbol, topic, ch)
+	}
+}
+
+// removeBook drops one book subscriber and the symbol's upstream
+// subscription with its last one. Callers hold s.mu.
+func (s *MarketStream) removeBook(symbol domain.Symbol, topic string, ch chan domain.BookUpdate) {
+	if s.books.Remove(topic, ch) {
+		delete(s.state, symbol)
+		_ = s.cf.unsubscribe(topic)
+	}
+}
+
+func (s *MarketStream) snapshot(symbol domain.Symbol, st *bookState) domain.BookUpdate {
+	book := st.book.Copy()
+	return domain.BookUpdate{Symbol: symbol, Sequence: st.seq, Snapshot: true, Bids: book.Bids, Asks: book.Asks, Time: time.Now()}
+}
@@ -0,0 +1,23 @@
+package bitpin
+
+import (
+	"fmt"
+	"strings"
+
+	"trade/internal/domain"
+)
+
+// symbolTranslator maps canonical symbols onto Bitpin's "BASE_QUOTE" names.
+type symbolTranslator struct{}
+
+func (symbolTranslator) ToExchange(s domain.Symbol) string {
+	return s.Base + "_" + s.Quote
+}
+
+func (symbolTranslator) FromExchange(native string) (domain.Symbol, error) {
+	base, quote, ok := strings.Cut(native, "_")
+	if !ok || base == "" || quote == "" {
+		return domain.Symbol{}, fmt.Errorf("bitpin: unrecognised market %q", native)
+	}
+	return domain.NewSymbol(base, quote), nil
+}
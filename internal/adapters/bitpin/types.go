@@ -0,0 +1,191 @@
+package bitpin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"trade/internal/domain"
+)
+
+// orderStatuses maps Bitpin order states onto domain statuses. Bitpin does
+// not distinguish partial fills; those are inferred from the dealt amount.
+var orderStatuses = map[string]domain.OrderStatus{
+	"initial":   domain.StatusNew,
+	"active":    domain.StatusNew,
+	"done":      domain.StatusFilled,
+	"closed":    domain.StatusFilled,
+	"canceled":  domain.StatusCanceled,
+	"cancelled": domain.StatusCanceled,
+	"rejected":  domain.StatusRejected,
+	"failed":    domain.StatusRejected,
+	"expired":   domain.StatusExpired,
+}
+
+// order is Bitpin's wire representation of an order.
+type order struct {
+	ID                int64  `json:"id"`
+	Symbol            string `json:"symbol"`
+	Side              string `json:"side"`
+	Type              string `json:"type"`
+	Price             string `json:"price"`
+	BaseAmount        string `json:"base_amount"`
+	DealedBaseAmount  string `json:"dealed_base_amount"`
+	DealedQuoteAmount string `json:"dealed_quote_amount"`
+	State             string `json:"state"`
+	Identifier        string `json:"identifier"`
+	CreatedAt         string `json:"created_at"`
+}
+
+// toDomain converts o, using fallback when the market name is not recognised.
+func (o order) toDomain(symbols domain.SymbolTranslator, fallback domain.Symbol) domain.OrderResponse {
+	qty, _ := domain.ParseDecimal(o.BaseAmount)
+	price, _ := domain.ParseDecimal(o.Price)
+	filled, _ := domain.ParseDecimal(o.DealedBaseAmount)
+	filledQuote, _ := domain.ParseDecimal(o.DealedQuoteAmount)
+	ts, _ := time.Parse(time.RFC3339, o.CreatedAt)
+	symbol, err := symbols.FromExchange(o.Symbol)
+	if err != nil {
+		symbol = fallback
+	}
+
+	return domain.OrderResponse{
+		ID:             strconv.FormatInt(o.ID, 10),
+		Symbol:         symbol,
+		Side:           domain.OrderSide(strings.ToUpper(o.Side)),
+		Type:           domain.OrderType(strings.ToUpper(o.Type)),
+		Quantity:       qty,
+		Price:          price,
+		Status:         domain.ResolveOrderStatus(orderStatuses, o.State, qty, filled),
+		RawStatus:      o.State,
+		FilledQuantity: filled,
+		AvgPrice:       domain.AveragePrice(filledQuote, filled),
+		Timestamp:      ts,
+	}
+}
+
+// fill is Bitpin's wire representation of one of our executions.
+type fill struct {
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"order_id"`
+	Symbol          string `json:"symbol"`
+	Side            string `json:"side"`
+	Price           string `json:"price"`
+	BaseAmount      string `json:"base_amount"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commission_asset"`
+	IsMaker         *bool  `json:"is_maker"`
+	CreatedAt       string `json:"created_at"`
+}
+
+func (f fill) toDomain(symbols domain.SymbolTranslator, fallback domain.Symbol) domain.Trade {
+	price, _ := domain.ParseDecimal(f.Price)
+	qty, _ := domain.ParseDecimal(f.BaseAmount)
+	fee, _ := domain.ParseDecimal(f.Commission)
+	ts, _ := time.Parse(time.RFC3339, f.CreatedAt)
+	symbol, err := symbols.FromExchange(f.Symbol)
+	if err != nil {
+		symbol = fallback
+	}
+
+	t := domain.Trade{
+		ID:       strconv.FormatInt(f.ID, 10),
+		OrderID:  strconv.FormatInt(f.OrderID, 10),
+		Symbol:   symbol,
+		Side:     domain.OrderSide(strings.ToUpper(f.Side)),
+		Price:    price,
+		Quantity: qty,
+		Fee:      fee,
+		FeeAsset: domain.CanonicalAsset(f.CommissionAsset),
+		Time:     ts,
+	}
+	if f.IsMaker != nil {
+		t.Liquidity = domain.LiquidityTaker
+		if *f.IsMaker {
+			t.Liquidity = domain.LiquidityMaker
+		}
+	}
+	return t
+}
+
+// wallet is Bitpin's wire representation of one asset balance; balance is
+// the total including the frozen part.
+type wallet struct {
+	Asset   string `json:"asset"`
+	Balance string `json:"balance"`
+	Frozen  string `json:"frozen"`
+}
+
+func (w wallet) toDomain() domain.Balance {
+	total, _ := domain.ParseDecimal(w.Balance)
+	frozen, _ := domain.ParseDecimal(w.Frozen)
+	return domain.Balance{Asset: domain.CanonicalAsset(w.Asset), Free: total.Sub(frozen), Locked: frozen}
+}
+
+// ticker is Bitpin's wire representation of a market ticker. The daily
+// change is published in percent and the timestamp in Unix seconds.
+type ticker struct {
+	Symbol           string         `json:"symbol"`
+	Price            domain.Decimal `json:"price"`
+	BestBid          domain.Decimal `json:"best_bid"`
+	BestAsk          domain.Decimal `json:"best_ask"`
+	High             domain.Decimal `json:"high"`
+	Low              domain.Decimal `json:"low"`
+	Volume           domain.Decimal `json:"volume"`
+	QuoteVolume      domain.Decimal `json:"quote_volume"`
+	DailyChangePrice domain.Decimal `json:"daily_change_price"`
+	Timestamp        float64        `json:"timestamp"`
+}
+
+func (t ticker) toDomain(symbol domain.Symbol) domain.Ticker {
+	return domain.Ticker{
+		Symbol:        symbol,
+		Last:          t.Price,
+		Bid:           t.BestBid,
+		Ask:           t.BestAsk,
+		High:          t.High,
+		Low:           t.Low,
+		Volume:        t.Volume,
+		QuoteVolume:   t.QuoteVolume,
+		ChangePercent: t.DailyChangePrice,
+		Time:          time.UnixMilli(int64(t.Timestamp * 1000)),
+	}
+}
+
+// match is Bitpin's wire representation of a public trade. Time is in Unix
+// seconds with a fractional part.
+type match struct {
+	ID         string         `json:"id"`
+	Price      domain.Decimal `json:"price"`
+	BaseAmount domain.Decimal `json:"base_amount"`
+	Side       string         `json:"side"`
+	Time       float64        `json:"time"`
+}
+
+func (m match) toDomain(symbol domain.Symbol) domain.PublicTrade {
+	return domain.PublicTrade{
+		ID:       m.ID,
+		Symbol:   symbol,
+		Side:     domain.OrderSide(strings.ToUpper(m.Side)),
+		Price:    m.Price,
+		Quantity: m.BaseAmount,
+		Time:     time.UnixMilli(int64(m.Time * 1000)),
+	}
+}
+
+// bookDiff is a publication on Bitpin's orderbook channel. Each level
+// carries the new total quantity at its price; Seq increases by one per
+// publication.
+type bookDiff struct {
+	Seq  uint64              `json:"seq"`
+	Bids [][2]domain.Decimal `json:"bids"`
+	Asks [][2]domain.Decimal `json:"asks"`
+}
+
+func depthLevels(raw [][2]domain.Decimal) []domain.DepthLevel {
+	out := make([]domain.DepthLevel, len(raw))
+	for i, lvl := range raw {
+		out[i] = domain.DepthLevel{Price: lvl[0], Quantity: lvl[1]}
+	}
+	return out
+}
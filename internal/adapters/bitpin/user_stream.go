@@ -0,0 +1,129 @@
+package bitpin
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"trade/internal/adapters/wsconn"
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// Private Centrifugo channels. The connection token identifies the user,
+// so the names carry no account id.
+const (
+	userOrdersChannel  = "$user:orders"
+	userFillsChannel   = "$user:fills"
+	userWalletsChannel = "$user:wallets"
+)
+
+var userChannels = []string{userOrdersChannel, userFillsChannel, userWalletsChannel}
+
+// userTopic is the single registry topic every user subscriber shares.
+const userTopic = "user"
+
+// UserStream implements domain.UserStreamPort over Bitpin's private
+// websocket, authenticating with the REST client's access token.
+type UserStream struct {
+	rest *BitpinAdapter
+	ctx  context.Context
+	cf   *centrifugo
+	log  ports.LoggerPort
+
+	mu   sync.Mutex
+	subs *wsconn.Topics[domain.UserEvent]
+}
+
+// NewUserStream connects to wsURL and keeps the connection up until ctx is
+// done. Each reconnect fetches a fresh token from rest's client.
+func NewUserStream(ctx context.Context, wsURL string, rest *BitpinAdapter, log ports.LoggerPort) *UserStream {
+	s := &UserStream{
+		rest: rest,
+		ctx:  ctx,
+		log:  log,
+		subs: wsconn.NewTopics[domain.UserEvent](streamBuffer),
+	}
+	s.cf = newCentrifugo(ctx, "bitpin private", wsURL, rest.client.AccessToken, s.channels, s.onPublication, log)
+	return s
+}
+
+func (s *UserStream) SubscribeUser(ctx context.Context) (<-chan domain.UserEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, first := s.subs.Add(userTopic)
+	if first {
+		for _, c := range userChannels {
+			if err := s.cf.subscribe(c); err != nil {
+				s.remove(ch)
+				return nil, err
+			}
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.remove(ch)
+	}()
+	return ch, nil
+}
+
+func (s *UserStream) channels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.subs.Has(userTopic) {
+		return nil
+	}
+	return userChannels
+}
+
+func (s *UserStream) onPublication(channel string, data json.RawMessage) {
+	ev := domain.UserEvent{Time: time.Now()}
+	var err error
+	switch channel {
+	case userOrdersChannel:
+		var o order
+		if err = json.Unmarshal(data, &o); err == nil {
+			resp := o.toDomain(s.rest.symbols, domain.Symbol{})
+			ev.Type, ev.Order = domain.UserEventOrder, &resp
+		}
+	case userFillsChannel:
+		var f fill
+		if err = json.Unmarshal(data, &f); err == nil {
+			t := f.toDomain(s.rest.symbols, domain.Symbol{})
+			ev.Type, ev.Trade = domain.UserEventFill, &t
+		}
+	case userWalletsChannel:
+		var w wallet
+		if err = json.Unmarshal(data, &w); err == nil {
+			b := w.toDomain()
+			ev.Type, ev.Balance = domain.UserEventBalance, &b
+		}
+	default:
+		return
+	}
+	if err != nil {
+		s.log.Error(s.ctx, "bitpin private stream decode error", ports.Fields{"channel": channel, "error": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.subs.Publish(userTopic, ev) {
+		s.log.Error(s.ctx, "user subscriber lagging, closing", nil)
+		s.remove(ch)
+	}
+}
+
+// remove drops one subscriber and the private channels with the last one.
+// Callers hold s.mu.
+func (s *UserStream) remove(ch chan domain.UserEvent) {
+	if s.subs.Remove(userTopic, ch) {
+		for _, c := range userChannels {
+			_ = s.cf.unsubscribe(c)
+		}
+	}
+}
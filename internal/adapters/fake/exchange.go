@@ -0,0 +1,297 @@
+// Package fake is an in-memory exchange for tests. It implements both
+// domain.ExchangePort and domain.MarketStreamPort. Orders never match on
+// their own: they rest as NEW until a test moves them on with SetOrder.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+)
+
+// ErrUnknownOrder is returned for orders the exchange never placed.
+var ErrUnknownOrder = errors.New("fake: unknown order")
+
+// Exchange is a scripted exchange. The exported fields may be set before
+// the exchange is shared; everything else goes through its methods.
+type Exchange struct {
+	Caps domain.Capabilities
+
+	mu       sync.Mutex
+	markets  map[domain.Symbol]domain.Market
+	books    map[domain.Symbol]domain.OrderBook
+	balances []domain.Balance
+	trades   []domain.Trade
+	orders   map[string]domain.OrderResponse
+	placed   []domain.OrderRequest
+	nextID   int
+	errs     map[string]error
+}
+
+func NewExchange() *Exchange {
+	return &Exchange{
+		markets: make(map[domain.Symbol]domain.Market),
+		books:   make(map[domain.Symbol]domain.OrderBook),
+		orders:  make(map[string]domain.OrderResponse),
+		errs:    make(map[string]error),
+	}
+}
+
+// AddMarket lists a tradable market with the given tick and step sizes and
+// an empty book.
+func (e *Exchange) AddMarket(m domain.Market) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.markets[m.Symbol] = m
+	if _, ok := e.books[m.Symbol]; !ok {
+		e.books[m.Symbol] = domain.OrderBook{Symbol: m.Symbol}
+	}
+}
+
+// SetBook replaces a market's REST book. Subscribed streams are not
+// notified.
+func (e *Exchange) SetBook(book domain.OrderBook) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.books[book.Symbol] = book
+}
+
+func (e *Exchange) SetBalances(balances ...domain.Balance) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.balances = append([]domain.Balance(nil), balances...)
+}
+
+func (e *Exchange) SetTrades(trades ...domain.Trade) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.trades = append([]domain.Trade(nil), trades...)
+}
+
+// SetOrder stores o as the exchange's current view of the order.
+func (e *Exchange) SetOrder(o domain.OrderResponse) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.orders[o.ID] = o
+}
+
+// Fail makes every later call of the named method, e.g. "GetOrder", return
+// err; a nil err clears it.
+func (e *Exchange) Fail(method string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err == nil {
+		delete(e.errs, method)
+		return
+	}
+	e.errs[method] = err
+}
+
+// Placed returns every request CreateOrder accepted, in order.
+func (e *Exchange) Placed() []domain.OrderRequest {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]domain.OrderRequest(nil), e.placed...)
+}
+
+// Order returns the exchange's view of an order.
+func (e *Exchange) Order(id string) (domain.OrderResponse, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, ok := e.orders[id]
+	return o, ok
+}
+
+// failure returns the error scripted for method; callers hold e.mu.
+func (e *Exchange) failure(method string) error {
+	return e.errs[method]
+}
+
+func (e *Exchange) Capabilities() domain.Capabilities { return e.Caps }
+
+func (e *Exchange) CreateOrder(ctx context.Context, req domain.OrderRequest) (domain.OrderResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.failure("CreateOrder"); err != nil {
+		return domain.OrderResponse{}, err
+	}
+	if _, ok := e.markets[req.Symbol]; !ok {
+		return domain.OrderResponse{}, fmt.Errorf("fake: unknown market %s", req.Symbol)
+	}
+	e.nextID++
+	o := domain.OrderResponse{
+		ID:        strconv.Itoa(e.nextID),
+		Symbol:    req.Symbol,
+		Side:      req.Side,
+		Type:      req.Type,
+		Quantity:  req.Quantity,
+		Status:    domain.StatusNew,
+		RawStatus: "new",
+		Timestamp: time.Now(),
+	}
+	if req.Price != nil {
+		o.Price = *req.Price
+	}
+	e.orders[o.ID] = o
+	e.placed = append(e.placed, req)
+	return o, nil
+}
+
+func (e *Exchange) CancelOrder(ctx context.Context, symbol domain.Symbol, orderID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.failure("CancelOrder"); err != nil {
+		return err
+	}
+	o, ok := e.orders[orderID]
+	if !ok {
+		return ErrUnknownOrder
+	}
+	if o.Status.IsFinal() {
+		return fmt.Errorf("fake: order %s is %s", orderID, o.Status)
+	}
+	o.Status, o.RawStatus = domain.StatusCanceled, "canceled"
+	e.orders[orderID] = o
+	return nil
+}
+
+func (e *Exchange) GetOrder(ctx context.Context, symbol domain.Symbol, orderID string) (domain.OrderResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.failure("GetOrder"); err != nil {
+		return domain.OrderResponse{}, err
+	}
+	o, ok := e.orders[orderID]
+	if !ok {
+		return domain.OrderResponse{}, ErrUnknownOrder
+	}
+	return o, nil
+}
+
+func (e *Exchange) ListOpenOrders(ctx context.Context, symbol *domain.Symbol) ([]domain.OrderResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.failure("ListOpenOrders"); err != nil {
+		return nil, err
+	}
+	var out []domain.OrderResponse
+	for _, o := range e.orders {
+		if !o.Status.IsFinal() && (symbol == nil || o.Symbol == *symbol) {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func (e *Exchange) GetOrderHistory(ctx context.Context, filter domain.HistoryFilter) ([]domain.OrderResponse, error) {
+	return nil, nil
+}
+
+// GetTrades pages through the scripted trades, which are kept in the order
+// given.
+func (e *Exchange) GetTrades(ctx context.Context, filter domain.HistoryFilter) ([]domain.Trade, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.failure("GetTrades"); err != nil {
+		return nil, err
+	}
+	var out []domain.Trade
+	for _, t := range e.trades {
+		if filter.Contains(t.Time) && (filter.Symbol == nil || t.Symbol == *filter.Symbol) {
+			out = append(out, t)
+		}
+	}
+	if filter.Offset >= len(out) {
+		return nil, nil
+	}
+	out = out[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(out) {
+		out = out[:filter.Limit]
+	}
+	return out, nil
+}
+
+func (e *Exchange) GetBalance(ctx context.Context) ([]domain.Balance, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.failure("GetBalance"); err != nil {
+		return nil, err
+	}
+	return append([]domain.Balance(nil), e.balances...), nil
+}
+
+func (e *Exchange) GetOrderBook(ctx context.Context, symbol domain.Symbol) (domain.OrderBook, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.failure("GetOrderBook"); err != nil {
+		return domain.OrderBook{}, err
+	}
+	book, ok := e.books[symbol]
+	if !ok {
+		return domain.OrderBook{}, fmt.Errorf("fake: unknown market %s", symbol)
+	}
+	return book.Copy(), nil
+}
+
+func (e *Exchange) GetRecentTrades(ctx context.Context, symbol domain.Symbol, limit int) ([]domain.PublicTrade, error) {
+	return nil, nil
+}
+
+func (e *Exchange) GetMarkets(ctx context.Context) ([]domain.Market, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.failure("GetMarkets"); err != nil {
+		return nil, err
+	}
+	out := make([]domain.Market, 0, len(e.markets))
+	for _, m := range e.markets {
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func (e *Exchange) GetTicker(ctx context.Context, symbol domain.Symbol) (domain.Ticker, error) {
+	return domain.Ticker{}, errors.New("fake: no tickers")
+}
+
+func (e *Exchange) GetTickers(ctx context.Context) ([]domain.Ticker, error) {
+	return nil, nil
+}
+
+func (e *Exchange) GetCandles(ctx context.Context, symbol domain.Symbol, resolution domain.Resolution, from, to time.Time) ([]domain.Candle, error) {
+	return nil, nil
+}
+
+// SubscribeBook fails for unknown markets and otherwise stays silent, so
+// local books keep the REST snapshot they were seeded with.
+func (e *Exchange) SubscribeBook(ctx context.Context, symbol domain.Symbol) (<-chan domain.BookUpdate, error) {
+	e.mu.Lock()
+	_, ok := e.books[symbol]
+	e.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown market %s", symbol)
+	}
+	return closeOnDone[domain.BookUpdate](ctx), nil
+}
+
+func (e *Exchange) SubscribeTrades(ctx context.Context, symbol domain.Symbol) (<-chan domain.PublicTrade, error) {
+	return closeOnDone[domain.PublicTrade](ctx), nil
+}
+
+func (e *Exchange) SubscribeTicker(ctx context.Context, symbol domain.Symbol) (<-chan domain.Ticker, error) {
+	return closeOnDone[domain.Ticker](ctx), nil
+}
+
+func closeOnDone[T any](ctx context.Context) <-chan T {
+	ch := make(chan T)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch
+}
@@ -0,0 +1,63 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"trade/internal/domain"
+)
+
+// HaltFile keeps the halt state as JSON in a single file.
+type HaltFile struct {
+	path string
+}
+
+func NewHaltFile(path string) *HaltFile {
+	return &HaltFile{path: path}
+}
+
+func (f *HaltFile) LoadHalt(ctx context.Context) (domain.HaltState, bool, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return domain.HaltState{}, false, nil
+	}
+	if err != nil {
+		return domain.HaltState{}, false, err
+	}
+	var state domain.HaltState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return domain.HaltState{}, false, fmt.Errorf("read %s: %w", f.path, err)
+	}
+	return state, true, nil
+}
+
+// SaveHalt writes the state to a temporary file first and renames it into
+// place, so a crash never leaves a half-written file.
+func (f *HaltFile) SaveHalt(ctx context.Context, state domain.HaltState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+
+	"trade/internal/ports"
+)
+
+// fieldsLogger adds a fixed set of fields to every entry.
+type fieldsLogger struct {
+	next   ports.LoggerPort
+	fields ports.Fields
+}
+
+// WithFields returns a logger that writes to log with fields added to
+// every entry, e.g. to tag everything an account's adapter logs.
+func WithFields(log ports.LoggerPort, fields ports.Fields) ports.LoggerPort {
+	return &fieldsLogger{next: log, fields: fields}
+}
+
+func (l *fieldsLogger) Info(ctx context.Context, msg string, fields ports.Fields) {
+	l.next.Info(ctx, msg, l.merge(fields))
+}
+
+func (l *fieldsLogger) Error(ctx context.Context, msg string, fields ports.Fields) {
+	l.next.Error(ctx, msg, l.merge(fields))
+}
+
+func (l *fieldsLogger) Debug(ctx context.Context, msg string, fields ports.Fields) {
+	l.next.Debug(ctx, msg, l.merge(fields))
+}
+
+func (l *fieldsLogger) merge(fields ports.Fields) ports.Fields {
+	out := make(ports.Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		out[k] = v
+	}
+	for k, v := range fields {
+		out[k] = v
+	}
+	return out
+}
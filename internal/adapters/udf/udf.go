@@ -0,0 +1,62 @@
+// Package udf decodes the TradingView UDF history endpoints that the
+// exchange adapters serve candles from.
+package udf
+
+import (
+	"fmt"
+	"time"
+
+	"trade/internal/domain"
+)
+
+// Resolutions maps candle widths onto UDF resolutions.
+var Resolutions = map[domain.Resolution]string{
+	domain.Resolution1m:  "1",
+	domain.Resolution5m:  "5",
+	domain.Resolution15m: "15",
+	domain.Resolution30m: "30",
+	domain.Resolution1h:  "60",
+	domain.Resolution4h:  "240",
+	domain.Resolution1d:  "1D",
+	domain.Resolution1w:  "1W",
+}
+
+// History is a UDF history response: parallel arrays of bar open times in
+// Unix seconds and OHLCV values. S is "no_data" for an empty range and
+// "error" with Errmsg set on failure.
+type History struct {
+	S      string           `json:"s"`
+	Errmsg string           `json:"errmsg"`
+	T      []int64          `json:"t"`
+	O      []domain.Decimal `json:"o"`
+	H      []domain.Decimal `json:"h"`
+	L      []domain.Decimal `json:"l"`
+	C      []domain.Decimal `json:"c"`
+	V      []domain.Decimal `json:"v"`
+}
+
+// Candles returns the bars opening within [from, to], dropping any the
+// server returned outside the requested window.
+func (h History) Candles(from, to time.Time) ([]domain.Candle, error) {
+	switch h.S {
+	case "ok":
+	case "no_data":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("candles unavailable: %s", h.Errmsg)
+	}
+	n := len(h.T)
+	if len(h.O) != n || len(h.H) != n || len(h.L) != n || len(h.C) != n || len(h.V) != n {
+		return nil, fmt.Errorf("candles: mismatched array lengths")
+	}
+
+	out := make([]domain.Candle, 0, n)
+	for i, secs := range h.T {
+		t := time.Unix(secs, 0)
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		out = append(out, domain.Candle{Time: t, Open: h.O[i], High: h.H[i], Low: h.L[i], Close: h.C[i], Volume: h.V[i]})
+	}
+	return out, nil
+}
@@ -0,0 +1,294 @@
+package wallex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"trade/internal/adapters/wsconn"
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// streamBuffer is the number of undelivered values a subscriber may have
+// queued before it counts as lagging.
+const streamBuffer = 256
+
+// Wallex channels are named "<MARKET>@<kind>".
+const (
+	buyDepthChannel  = "buyDepth"
+	sellDepthChannel = "sellDepth"
+	tradeChannel     = "trade"
+	tickerChannel    = "marketCap"
+)
+
+// Socket.IO (Engine.IO v4) packet prefixes used on the Wallex socket.
+const (
+	eioOpen       = "0"
+	eioPing       = "2"
+	eioPong       = "3"
+	sioConnect    = "40"
+	sioEvent      = "42"
+	sioConnectErr = "44"
+)
+
+// depthState holds the latest full side snapshots of one market. Wallex
+// publishes each side separately as its top levels, so a book update is
+// emitted once both sides are known and on every change after that.
+type depthState struct {
+	bids, asks         []domain.DepthLevel
+	haveBids, haveAsks bool
+	seq                uint64
+}
+
+// MarketStream implements domain.MarketStreamPort over Wallex's public
+// Socket.IO websocket.
+type MarketStream struct {
+	symbols domain.SymbolTranslator
+	ctx     context.Context
+	conn    *wsconn.Conn
+	log     ports.LoggerPort
+
+	mu      sync.Mutex
+	books   *wsconn.Topics[domain.BookUpdate]
+	trades  *wsconn.Topics[domain.PublicTrade]
+	tickers *wsconn.Topics[domain.Ticker]
+	depth   map[domain.Symbol]*depthState
+}
+
+// NewMarketStream connects to wsURL (the Socket.IO websocket endpoint) and
+// keeps the connection up until ctx is done.
+func NewMarketStream(ctx context.Context, wsURL string, log ports.LoggerPort) *MarketStream {
+	s := &MarketStream{
+		symbols: symbolTranslator{},
+		ctx:     ctx,
+		log:     log,
+		books:   wsconn.NewTopics[domain.BookUpdate](streamBuffer),
+		trades:  wsconn.NewTopics[domain.PublicTrade](streamBuffer),
+		tickers: wsconn.NewTopics[domain.Ticker](streamBuffer),
+		depth:   make(map[domain.Symbol]*depthState),
+	}
+	s.conn = wsconn.Dial(ctx, "wallex", wsconn.Options{URL: wsURL, OnMessage: s.onMessage}, log)
+	return s
+}
+
+// SubscribeBook subscribes to both depth sides of symbol. Every update is a
+// snapshot, so a reconnect needs no special handling.
+func (s *MarketStream) SubscribeBook(ctx context.Context, symbol domain.Symbol) (<-chan domain.BookUpdate, error) {
+	topic := s.channel(symbol, buyDepthChannel)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, first := s.books.Add(topic)
+	if first {
+		s.depth[symbol] = &depthState{}
+		if err := s.join(topic, s.channel(symbol, sellDepthChannel)); err != nil {
+			s.books.Remove(topic, ch)
+			delete(s.depth, symbol)
+			return nil, err
+		}
+	} else if st := s.depth[symbol]; st.haveBids && st.haveAsks {
+		wsconn.Offer(ch, st.update(symbol))
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.removeBook(symbol, topic, ch)
+	}()
+	return ch, nil
+}
+
+func (s *MarketStream) SubscribeTrades(ctx context.Context, symbol domain.Symbol) (<-chan domain.PublicTrade, error) {
+	return subscribe(ctx, s, s.trades, s.channel(symbol, tradeChannel))
+}
+
+func (s *MarketStream) SubscribeTicker(ctx context.Context, symbol domain.Symbol) (<-chan domain.Ticker, error) {
+	return subscribe(ctx, s, s.tickers, s.channel(symbol, tickerChannel))
+}
+
+func subscribe[T any](ctx context.Context, s *MarketStream, topics *wsconn.Topics[T], topic string) (<-chan T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, first := topics.Add(topic)
+	if first {
+		if err := s.join(topic); err != nil {
+			topics.Remove(topic, ch)
+			return nil, err
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if topics.Remove(topic, ch) {
+			s.leave(topic)
+		}
+	}()
+	return ch, nil
+}
+
+func (s *MarketStream) channel(symbol domain.Symbol, kind string) string {
+	return s.symbols.ToExchange(symbol) + "@" + kind
+}
+
+func (s *MarketStream) onMessage(data []byte) {
+	msg := string(data)
+	switch {
+	case msg == eioPing:
+		_ = s.conn.Send([]byte(eioPong))
+	case strings.HasPrefix(msg, sioConnectErr):
+		s.log.Error(s.ctx, "wallex stream rejected", ports.Fields{"error": msg[len(sioConnectErr):]})
+	case strings.HasPrefix(msg, sioConnect):
+		s.resubscribe()
+	case strings.HasPrefix(msg, sioEvent):
+		s.onEvent([]byte(msg[len(sioEvent):]))
+	case strings.HasPrefix(msg, eioOpen):
+		_ = s.conn.Send([]byte(sioConnect))
+	}
+}
+
+// resubscribe replays every subscription once the Socket.IO namespace is
+// connected.
+func (s *MarketStream) resubscribe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var channels []string
+	for symbol := range s.depth {
+		channels = append(channels, s.channel(symbol, buyDepthChannel), s.channel(symbol, sellDepthChannel))
+	}
+	channels = append(channels, s.trades.Names()...)
+	channels = append(channels, s.tickers.Names()...)
+	if err := s.join(channels...); err != nil {
+		s.log.Error(s.ctx, "wallex resubscribe failed", ports.Fields{"error": err.Error()})
+	}
+}
+
+// onEvent handles `["Broadcaster", channel, data]` events.
+func (s *MarketStream) onEvent(payload []byte) {
+	var event []json.RawMessage
+	if err := json.Unmarshal(payload, &event); err != nil || len(event) < 3 {
+		return
+	}
+	var channel string
+	if err := json.Unmarshal(event[1], &channel); err != nil {
+		return
+	}
+	native, kind, ok := strings.Cut(channel, "@")
+	if !ok {
+		return
+	}
+	symbol, err := s.symbols.FromExchange(native)
+	if err != nil {
+		return
+	}
+
+	data := event[2]
+	var decodeErr error
+	switch kind {
+	case buyDepthChannel, sellDepthChannel:
+		var levels []struct {
+			Price    domain.Decimal `json:"price"`
+			Quantity domain.Decimal `json:"quantity"`
+		}
+		if decodeErr = json.Unmarshal(data, &levels); decodeErr == nil {
+			side := make([]domain.DepthLevel, len(levels))
+			for i, lvl := range levels {
+				side[i] = domain.DepthLevel{Price: lvl.Price, Quantity: lvl.Quantity}
+			}
+			s.onDepth(symbol, kind == buyDepthChannel, side)
+		}
+	case tradeChannel:
+		var t publicTrade
+		if decodeErr = json.Unmarshal(data, &t); decodeErr == nil {
+			s.mu.Lock()
+			s.trades.Publish(channel, t.toDomain(symbol))
+			s.mu.Unlock()
+		}
+	case tickerChannel:
+		var st marketStats
+		if decodeErr = json.Unmarshal(data, &st); decodeErr == nil {
+			s.mu.Lock()
+			s.tickers.Publish(channel, st.toDomain(symbol, time.Now()))
+			s.mu.Unlock()
+		}
+	}
+	if decodeErr != nil {
+		s.log.Error(s.ctx, "wallex stream decode error", ports.Fields{"channel": channel, "error": decodeErr.Error()})
+	}
+}
+
+func (s *MarketStream) onDepth(symbol domain.Symbol, bids bool, levels []domain.DepthLevel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.depth[symbol]
+	if !ok {
+		return
+	}
+	if bids {
+		st.bids, st.haveBids = levels, true
+	} else {
+		st.asks, st.haveAsks = levels, true
+	}
+	if !st.haveBids || !st.haveAsks {
+		return
+	}
+
+	st.seq++
+	topic := s.channel(symbol, buyDepthChannel)
+	for _, ch := range s.books.Publish(topic, st.update(symbol)) {
+		s.log.Error(s.ctx, "book subscriber lagging, closing", ports.Fields{"symbol": symbol})
+		s.removeBook(symbol, topic, ch)
+	}
+}
+
+// removeBook drops one book subscriber and the market's depth channels
+// with its last one. Callers hold s.mu.
+func (s *MarketStream) removeBook(symbol domain.Symbol, topic string, ch chan domain.BookUpdate) {
+	if s.books.Remove(topic, ch) {
+		delete(s.depth, symbol)
+		s.leave(topic, s.channel(symbol, sellDepthChannel))
+	}
+}
+
+// join and leave are no-ops while disconnected; subscriptions are replayed
+// on reconnect.
+func (s *MarketStream) join(channels ...string) error {
+	for _, ch := range channels {
+		if err := s.emit("subscribe", ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MarketStream) leave(channels ...string) {
+	for _, ch := range channels {
+		_ = s.emit("unsubscribe", ch)
+	}
+}
+
+func (s *MarketStream) emit(event, channel string) error {
+	payload, _ := json.Marshal([]interface{}{event, map[string]string{"channel": channel}})
+	err := s.conn.Send(append([]byte(sioEvent), payload...))
+	if errors.Is(err, wsconn.ErrNotConnected) {
+		return nil
+	}
+	return err
+}
+
+func (st *depthState) update(symbol domain.Symbol) domain.BookUpdate {
+	return domain.BookUpdate{
+		Symbol:   symbol,
+		Sequence: st.seq,
+		Snapshot: true,
+		Bids:     append([]domain.DepthLevel(nil), st.bids...),
+		Asks:     append([]domain.DepthLevel(nil), st.asks...),
+		Time:     time.Now(),
+	}
+}
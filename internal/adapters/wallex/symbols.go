@@ -0,0 +1,38 @@
+package wallex
+
+import (
+	"fmt"
+	"strings"
+
+	"trade/internal/domain"
+)
+
+// quoteAssets lists Wallex quote currencies, longest first so that
+// suffix matching is unambiguous.
+var quoteAssets = []string{"USDT", "TMN"}
+
+// symbolTranslator maps canonical symbols onto Wallex's concatenated
+// names, e.g. BTC-IRT <-> BTCTMN.
+type symbolTranslator struct{}
+
+func (symbolTranslator) ToExchange(s domain.Symbol) string {
+	return s.Base + toWallexAsset(s.Quote)
+}
+
+func (symbolTranslator) FromExchange(native string) (domain.Symbol, error) {
+	native = strings.ToUpper(native)
+	for _, q := range quoteAssets {
+		if base, ok := strings.CutSuffix(native, q); ok && base != "" {
+			return domain.NewSymbol(base, q), nil
+		}
+	}
+	return domain.Symbol{}, fmt.Errorf("wallex: unrecognised market %q", native)
+}
+
+// toWallexAsset is the inverse of domain.CanonicalAsset for Wallex.
+func toWallexAsset(asset string) string {
+	if asset == "IRT" {
+		return "TMN"
+	}
+	return asset
+}
@@ -0,0 +1,222 @@
+package wallex
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"trade/internal/domain"
+)
+
+// orderStatuses maps Wallex order statuses onto domain statuses.
+var orderStatuses = map[string]domain.OrderStatus{
+	"new":              domain.StatusNew,
+	"partially_filled": domain.StatusPartiallyFilled,
+	"filled":           domain.StatusFilled,
+	"pending_cancel":   domain.StatusNew,
+	"canceled":         domain.StatusCanceled,
+	"rejected":         domain.StatusRejected,
+	"expired":          domain.StatusExpired,
+}
+
+// order is Wallex's wire representation of an order. Wallex identifies
+// orders by their client order id.
+type order struct {
+	Symbol        string `json:"symbol"`
+	Type          string `json:"type"`
+	Side          string `json:"side"`
+	Price         string `json:"price"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	ExecutedSum   string `json:"executedSum"`
+	TransactTime  int64  `json:"transactTime"`
+	ClientOrderId string `json:"clientOrderId"`
+	Status        string `json:"status"`
+	Active        bool   `json:"active"`
+}
+
+// toDomain converts o, using fallback when the market name is not recognised.
+func (o order) toDomain(symbols domain.SymbolTranslator, fallback domain.Symbol) domain.OrderResponse {
+	origQty, _ := domain.ParseDecimal(o.OrigQty)
+	price, _ := domain.ParseDecimal(o.Price)
+	filled, _ := domain.ParseDecimal(o.ExecutedQty)
+	filledQuote, _ := domain.ParseDecimal(o.ExecutedSum)
+	symbol, err := symbols.FromExchange(o.Symbol)
+	if err != nil {
+		symbol = fallback
+	}
+
+	return domain.OrderResponse{
+		ID:             o.ClientOrderId,
+		Symbol:         symbol,
+		Side:           domain.OrderSide(o.Side),
+		Type:           domain.OrderType(o.Type),
+		Quantity:       origQty,
+		Price:          price,
+		Status:         domain.ResolveOrderStatus(orderStatuses, o.Status, origQty, filled),
+		RawStatus:      o.Status,
+		FilledQuantity: filled,
+		AvgPrice:       domain.AveragePrice(filledQuote, filled),
+		Timestamp:      time.Unix(o.TransactTime, 0),
+	}
+}
+
+// trade is Wallex's wire representation of one of our executions.
+type trade struct {
+	Symbol        string `json:"symbol"`
+	Quantity      string `json:"quantity"`
+	Price         string `json:"price"`
+	Fee           string `json:"fee"`
+	FeeAsset      string `json:"feeAsset"`
+	Timestamp     string `json:"timestamp"`
+	IsBuyer       bool   `json:"isBuyer"`
+	IsMaker       *bool  `json:"isMaker"`
+	ClientOrderId string `json:"clientOrderId"`
+}
+
+// toDomain converts t. Wallex does not number individual fills, so the
+// ID is derived from the order and execution time.
+func (t trade) toDomain(symbols domain.SymbolTranslator, fallback domain.Symbol) domain.Trade {
+	price, _ := domain.ParseDecimal(t.Price)
+	qty, _ := domain.ParseDecimal(t.Quantity)
+	fee, _ := domain.ParseDecimal(t.Fee)
+	ts, _ := time.Parse(time.RFC3339, t.Timestamp)
+	symbol, err := symbols.FromExchange(t.Symbol)
+	if err != nil {
+		symbol = fallback
+	}
+	side := domain.SideSell
+	if t.IsBuyer {
+		side = domain.SideBuy
+	}
+
+	out := domain.Trade{
+		ID:       t.ClientOrderId + "-" + strconv.FormatInt(ts.UnixMilli(), 10),
+		OrderID:  t.ClientOrderId,
+		Symbol:   symbol,
+		Side:     side,
+		Price:    price,
+		Quantity: qty,
+		Fee:      fee,
+		FeeAsset: domain.CanonicalAsset(t.FeeAsset),
+		Time:     ts,
+	}
+	if t.IsMaker != nil {
+		out.Liquidity = domain.LiquidityTaker
+		if *t.IsMaker {
+			out.Liquidity = domain.LiquidityMaker
+		}
+	}
+	return out
+}
+
+// defaultPageLimit is the page size used when a filter sets none.
+const defaultPageLimit = 50
+
+// pageWindow reads rows offset to offset+limit of a Wallex listing with
+// fetch, which returns one page of the given size. Wallex pages by page
+// number, so an unaligned window is read from the two pages of size limit
+// that cover it.
+func pageWindow[T any](offset, limit int, fetch func(page, perPage int) ([]T, error)) ([]T, error) {
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	page, skip := offset/limit+1, offset%limit
+	rows, err := fetch(page, limit)
+	if err != nil || skip == 0 {
+		return rows, err
+	}
+	if len(rows) <= skip {
+		return nil, nil
+	}
+	full := len(rows) == limit
+	rows = rows[skip:]
+	if !full {
+		return rows, nil
+	}
+	next, err := fetch(page+1, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(next) > skip {
+		next = next[:skip]
+	}
+	return append(rows, next...), nil
+}
+
+// pageQuery sets Wallex's page and per_page parameters on a copy of q.
+func pageQuery(q url.Values, page, perPage int) url.Values {
+	out := url.Values{}
+	for k, v := range q {
+		out[k] = v
+	}
+	out.Set("page", strconv.Itoa(page))
+	out.Set("per_page", strconv.Itoa(perPage))
+	return out
+}
+
+// marketStats is the 24h summary embedded in each Wallex market. 24h_ch is
+// the change in percent.
+type marketStats struct {
+	BidPrice    statValue `json:"bidPrice"`
+	AskPrice    statValue `json:"askPrice"`
+	LastPrice   statValue `json:"lastPrice"`
+	High        statValue `json:"24h_highPrice"`
+	Low         statValue `json:"24h_lowPrice"`
+	Volume      statValue `json:"24h_volume"`
+	QuoteVolume statValue `json:"24h_quoteVolume"`
+	Change      statValue `json:"24h_ch"`
+}
+
+func (s marketStats) toDomain(symbol domain.Symbol, at time.Time) domain.Ticker {
+	return domain.Ticker{
+		Symbol:        symbol,
+		Last:          s.LastPrice.Decimal,
+		Bid:           s.BidPrice.Decimal,
+		Ask:           s.AskPrice.Decimal,
+		High:          s.High.Decimal,
+		Low:           s.Low.Decimal,
+		Volume:        s.Volume.Decimal,
+		QuoteVolume:   s.QuoteVolume.Decimal,
+		ChangePercent: s.Change.Decimal,
+		Time:          at,
+	}
+}
+
+// statValue is a market statistic, which Wallex reports as "-" for markets
+// without trades.
+type statValue struct {
+	domain.Decimal
+}
+
+func (v *statValue) UnmarshalJSON(data []byte) error {
+	if string(data) == `"-"` {
+		return nil
+	}
+	return v.Decimal.UnmarshalJSON(data)
+}
+
+// publicTrade is Wallex's wire representation of a trade on the public
+// tape. isBuyOnMaker means the resting order was the buyer, so the taker
+// sold.
+type publicTrade struct {
+	Quantity     domain.Decimal `json:"quantity"`
+	Price        domain.Decimal `json:"price"`
+	IsBuyOnMaker bool           `json:"isBuyOnMaker"`
+	Timestamp    string         `json:"timestamp"`
+}
+
+func (t publicTrade) toDomain(symbol domain.Symbol) domain.PublicTrade {
+	ts, _ := time.Parse(time.RFC3339, t.Timestamp)
+	side := domain.SideBuy
+	if t.IsBuyOnMaker {
+		side = domain.SideSell
+	}
+	return domain.PublicTrade{
+		Symbol:   symbol,
+		Side:     side,
+		Price:    t.Price,
+		Quantity: t.Quantity,
+		Time:     ts,
+	}
+}
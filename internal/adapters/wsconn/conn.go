@@ -0,0 +1,149 @@
+// Package wsconn holds the websocket plumbing shared by the exchange
+// streaming adapters: a connection that redials on its own and a per-topic
+// subscriber registry.
+package wsconn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"trade/internal/ports"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+	// readTimeout drops connections that have gone silent; both exchanges
+	// ping well within it.
+	readTimeout  = 60 * time.Second
+	writeTimeout = 10 * time.Second
+)
+
+// ErrNotConnected is returned by Send while the connection is down.
+var ErrNotConnected = errors.New("websocket not connected")
+
+type Options struct {
+	URL string
+	// Header is called before every dial, so credentials can be refreshed.
+	Header func(ctx context.Context) http.Header
+	// OnConnect runs after every successful dial, typically to
+	// authenticate and re-send subscriptions.
+	OnConnect func(ctx context.Context) error
+	// OnMessage receives every data frame on the read goroutine and must
+	// not block.
+	OnMessage func(data []byte)
+}
+
+// Conn is a websocket client connection that redials with exponential
+// backoff whenever it drops, until its context is done.
+type Conn struct {
+	name string
+	opts Options
+	log  ports.LoggerPort
+
+	mu sync.Mutex
+	ws *websocket.Conn
+}
+
+// Dial starts connecting in the background and returns immediately.
+func Dial(ctx context.Context, name string, opts Options, log ports.LoggerPort) *Conn {
+	c := &Conn{name: name, opts: opts, log: log}
+	go c.run(ctx)
+	return c
+}
+
+// Send writes one text frame.
+func (c *Conn) Send(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ws == nil {
+		return ErrNotConnected
+	}
+	_ = c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *Conn) SendJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Send(data)
+}
+
+func (c *Conn) run(ctx context.Context) {
+	backoff := minBackoff
+	for {
+		if c.session(ctx) {
+			backoff = minBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// session dials once and reads until the connection drops. It reports
+// whether the connection was established, so the backoff can reset.
+func (c *Conn) session(ctx context.Context) bool {
+	var header http.Header
+	if c.opts.Header != nil {
+		header = c.opts.Header(ctx)
+	}
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, c.opts.URL, header)
+	if err != nil {
+		c.log.Error(ctx, c.name+" websocket dial failed", ports.Fields{"error": err.Error()})
+		return false
+	}
+	c.log.Info(ctx, c.name+" websocket connected", nil)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	c.mu.Lock()
+	c.ws = ws
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.ws = nil
+		c.mu.Unlock()
+		ws.Close()
+	}()
+
+	if c.opts.OnConnect != nil {
+		if err := c.opts.OnConnect(ctx); err != nil {
+			c.log.Error(ctx, c.name+" websocket setup failed", ports.Fields{"error": err.Error()})
+			return false
+		}
+	}
+
+	for {
+		_ = ws.SetReadDeadline(time.Now().Add(readTimeout))
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			if ctx.Err() == nil {
+				c.log.Error(ctx, c.name+" websocket dropped", ports.Fields{"error": err.Error()})
+			}
+			return true
+		}
+		c.opts.OnMessage(data)
+	}
+}
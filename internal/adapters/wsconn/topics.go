@@ -0,0 +1,82 @@
+package wsconn
+
+// Topics fans values out to the subscribers of each upstream topic. It is
+// not safe for concurrent use; callers serialise access with their own
+// lock so that publishing stays ordered with their other state.
+type Topics[T any] struct {
+	buffer int
+	subs   map[string]map[chan T]struct{}
+}
+
+// NewTopics creates a registry whose subscriber channels hold buffer values.
+func NewTopics[T any](buffer int) *Topics[T] {
+	return &Topics[T]{buffer: buffer, subs: make(map[string]map[chan T]struct{})}
+}
+
+// Add registers a new subscriber and reports whether it is the topic's
+// first, in which case the caller should subscribe upstream.
+func (t *Topics[T]) Add(topic string) (ch chan T, first bool) {
+	set, ok := t.subs[topic]
+	if !ok {
+		set = make(map[chan T]struct{})
+		t.subs[topic] = set
+	}
+	ch = make(chan T, t.buffer)
+	set[ch] = struct{}{}
+	return ch, !ok
+}
+
+// Remove closes and unregisters ch and reports whether the topic has no
+// subscribers left. Removing a channel twice is harmless.
+func (t *Topics[T]) Remove(topic string, ch chan T) (last bool) {
+	set, ok := t.subs[topic]
+	if !ok {
+		return false
+	}
+	if _, ok := set[ch]; !ok {
+		return false
+	}
+	delete(set, ch)
+	close(ch)
+	if len(set) > 0 {
+		return false
+	}
+	delete(t.subs, topic)
+	return true
+}
+
+// Publish delivers v to every subscriber of topic without blocking and
+// returns the subscribers whose buffers were full.
+func (t *Topics[T]) Publish(topic string, v T) (lagging []chan T) {
+	for ch := range t.subs[topic] {
+		if !Offer(ch, v) {
+			lagging = append(lagging, ch)
+		}
+	}
+	return lagging
+}
+
+// Has reports whether topic has any subscribers.
+func (t *Topics[T]) Has(topic string) bool {
+	_, ok := t.subs[topic]
+	return ok
+}
+
+// Names lists the topics that have subscribers.
+func (t *Topics[T]) Names() []string {
+	out := make([]string, 0, len(t.subs))
+	for topic := range t.subs {
+		out = append(out, topic)
+	}
+	return out
+}
+
+// Offer sends v on ch unless its buffer is full.
+func Offer[T any](ch chan T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	default:
+		return false
+	}
+}
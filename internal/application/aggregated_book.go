@@ -0,0 +1,42 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// GetAggregatedOrderBook merges symbol's book on every configured exchange.
+// Exchanges whose book cannot be read are reported in the result's Errors
+// and left out; it fails only when no book could be read.
+func (s *TradingService) GetAggregatedOrderBook(ctx context.Context, symbol domain.Symbol) (domain.AggregatedBook, error) {
+	exchanges := s.exchanges.All()
+	books := make([]domain.OrderBook, len(exchanges))
+	errs := make([]error, len(exchanges))
+	forEachLimit(len(exchanges), len(exchanges), func(i int) {
+		books[i], errs[i] = exchanges[i].Books.OrderBook(ctx, symbol)
+	})
+
+	byName := make(map[string]domain.OrderBook, len(exchanges))
+	failed := make(map[string]string)
+	for i, x := range exchanges {
+		if errs[i] != nil {
+			s.log.Error(ctx, "GetAggregatedOrderBook: exchange book failed", ports.Fields{"exchange": x.Name, "symbol": symbol, "error": errs[i]})
+			failed[x.Name] = errs[i].Error()
+			continue
+		}
+		byName[x.Name] = books[i]
+	}
+	if len(byName) == 0 {
+		return domain.AggregatedBook{}, fmt.Errorf("GetAggregatedOrderBook failed: %w", errors.Join(errs...))
+	}
+
+	agg := domain.MergeBooks(symbol, byName)
+	if len(failed) > 0 {
+		agg.Errors = failed
+	}
+	return agg, nil
+}
@@ -0,0 +1,286 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// arbitrageCooldown is how long a symbol is left alone after the scanner
+// traded it, so that balances and books can catch up with the fills.
+const arbitrageCooldown = 30 * time.Second
+
+// ArbitrageConfig holds the settings of ArbitrageScanner.
+type ArbitrageConfig struct {
+	// Symbols lists the markets to compare; the scanner is idle without.
+	Symbols []domain.Symbol
+	// Interval is how often the books are compared.
+	Interval time.Duration
+	// MinSpread is the smallest NetSpread reported, as a fraction.
+	MinSpread domain.Decimal
+	// TransferCosts holds the cost, in units of the asset, of moving an
+	// asset between exchanges, keyed by canonical asset.
+	TransferCosts map[string]domain.Decimal
+	// Execute places both legs of every opportunity found.
+	Execute bool
+	// Account names the account traded on each exchange when Execute is
+	// set; it may be empty on exchanges with a single account.
+	Account string
+}
+
+// ArbitrageScanner compares the local books of the configured symbols on
+// every pair of exchanges and keeps the opportunities whose net spread,
+// after taker fees and transfer cost, reaches MinSpread. New
+// opportunities are logged. In execution mode each is traded right away,
+// unless trading is halted, as two immediate-or-cancel limit orders
+// placed through CreateOrder.
+type ArbitrageScanner struct {
+	exchanges *ExchangeRegistry
+	svc       *TradingService
+	cfg       ArbitrageConfig
+	log       ports.LoggerPort
+
+	mu      sync.RWMutex
+	current []domain.ArbitrageOpportunity
+	cooling map[domain.Symbol]time.Time
+}
+
+// NewArbitrageScanner starts the scanner when there are symbols to watch
+// and at least two exchanges; it runs until ctx is done.
+func NewArbitrageScanner(ctx context.Context, exchanges *ExchangeRegistry, svc *TradingService, cfg ArbitrageConfig, log ports.LoggerPort) *ArbitrageScanner {
+	s := &ArbitrageScanner{
+		exchanges: exchanges,
+		svc:       svc,
+		cfg:       cfg,
+		log:       log,
+		cooling:   make(map[domain.Symbol]time.Time),
+	}
+	if len(cfg.Symbols) > 0 && len(exchanges.Names()) > 1 {
+		go s.run(ctx)
+		log.Info(ctx, "arbitrage scanner started", ports.Fields{"symbols": len(cfg.Symbols), "execute": cfg.Execute})
+	}
+	return s
+}
+
+// Opportunities returns the opportunities found by the last scan, best
+// net spread first.
+func (s *ArbitrageScanner) Opportunities() []domain.ArbitrageOpportunity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]domain.ArbitrageOpportunity(nil), s.current...)
+}
+
+func (s *ArbitrageScanner) run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.scan(ctx)
+		}
+	}
+}
+
+// scan compares every symbol once and replaces the published list.
+func (s *ArbitrageScanner) scan(ctx context.Context) {
+	found := make([][]domain.ArbitrageOpportunity, len(s.cfg.Symbols))
+	forEachLimit(len(s.cfg.Symbols), len(s.cfg.Symbols), func(i int) {
+		found[i] = s.compare(ctx, s.cfg.Symbols[i])
+	})
+
+	var all []domain.ArbitrageOpportunity
+	for _, f := range found {
+		all = append(all, f...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].NetSpread.GreaterThan(all[j].NetSpread) })
+
+	s.mu.Lock()
+	previous := make(map[string]bool, len(s.current))
+	for _, o := range s.current {
+		previous[arbitrageKey(o)] = true
+	}
+	s.current = all
+	s.mu.Unlock()
+
+	for _, o := range all {
+		if previous[arbitrageKey(o)] {
+			continue
+		}
+		s.log.Info(ctx, "arbitrage opportunity", ports.Fields{
+			"symbol":    o.Symbol,
+			"buy":       o.BuyExchange,
+			"sell":      o.SellExchange,
+			"quantity":  o.Quantity,
+			"netProfit": o.NetProfit,
+			"netSpread": o.NetSpread,
+		})
+	}
+	if s.cfg.Execute && !s.svc.HaltState().Halted {
+		for _, o := range all {
+			s.execute(ctx, o)
+		}
+	}
+}
+
+// compare returns the opportunities on symbol that reach MinSpread, at
+// most one per ordered pair of exchanges.
+func (s *ArbitrageScanner) compare(ctx context.Context, symbol domain.Symbol) []domain.ArbitrageOpportunity {
+	exchanges := s.exchanges.All()
+	venues := make([]*domain.ArbitrageVenue, len(exchanges))
+	forEachLimit(len(exchanges), len(exchanges), func(i int) {
+		x := exchanges[i]
+		book, err := x.Books.OrderBook(ctx, symbol)
+		if err != nil {
+			s.log.Error(ctx, "arbitrage: order book unavailable", ports.Fields{"exchange": x.Name, "symbol": symbol, "error": err})
+			return
+		}
+		venues[i] = &domain.ArbitrageVenue{Exchange: x.Name, Book: book, TakerFee: x.TakerFee}
+	})
+
+	now := time.Now()
+	transfer := s.cfg.TransferCosts[symbol.Base]
+	var out []domain.ArbitrageOpportunity
+	for _, buy := range venues {
+		for _, sell := range venues {
+			if buy == nil || sell == nil || buy == sell {
+				continue
+			}
+			o, ok := domain.FindArbitrage(symbol, *buy, *sell, transfer)
+			if !ok || o.NetSpread.LessThan(s.cfg.MinSpread) {
+				continue
+			}
+			o.SeenAt = now
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// execute buys on one exchange and sells on the other at the
+// opportunity's limit prices, sized to what both accounts' free balances
+// allow. The legs are sent together and are not unwound if only one
+// fills; both outcomes are logged.
+func (s *ArbitrageScanner) execute(ctx context.Context, o domain.ArbitrageOpportunity) {
+	s.mu.Lock()
+	if until, ok := s.cooling[o.Symbol]; ok && time.Now().Before(until) {
+		s.mu.Unlock()
+		return
+	}
+	s.cooling[o.Symbol] = time.Now().Add(arbitrageCooldown)
+	s.mu.Unlock()
+
+	qty, err := s.executableQuantity(ctx, o)
+	if err != nil {
+		s.log.Error(ctx, "arbitrage: not executed", ports.Fields{"symbol": o.Symbol, "buy": o.BuyExchange, "sell": o.SellExchange, "error": err})
+		return
+	}
+
+	legs := []struct {
+		exchange string
+		side     domain.OrderSide
+		price    domain.Decimal
+	}{
+		{o.BuyExchange, domain.SideBuy, o.BuyPrice},
+		{o.SellExchange, domain.SideSell, o.SellPrice},
+	}
+	forEachLimit(len(legs), len(legs), func(i int) {
+		leg := legs[i]
+		legCtx := WithExchange(ctx, leg.exchange)
+		if s.cfg.Account != "" {
+			legCtx = WithAccount(legCtx, s.cfg.Account)
+		}
+		price := leg.price
+		resp, err := s.svc.CreateOrder(legCtx, domain.OrderRequest{
+			Symbol:      o.Symbol,
+			Side:        leg.side,
+			Type:        domain.TypeLimit,
+			Quantity:    qty,
+			Price:       &price,
+			TimeInForce: domain.TimeInForceIOC,
+			Timestamp:   time.Now(),
+		})
+		if err != nil {
+			s.log.Error(ctx, "arbitrage: leg failed", ports.Fields{"exchange": leg.exchange, "symbol": o.Symbol, "side": leg.side, "quantity": qty, "error": err})
+			return
+		}
+		s.log.Info(ctx, "arbitrage: leg placed", ports.Fields{"exchange": leg.exchange, "symbol": o.Symbol, "side": leg.side, "orderID": resp.ID, "status": resp.Status, "filled": resp.FilledQuantity})
+	})
+}
+
+// executableQuantity caps the opportunity's quantity by the free quote
+// balance on the buying exchange and the free base balance on the selling
+// one, then snaps it to both markets' step sizes.
+func (s *ArbitrageScanner) executableQuantity(ctx context.Context, o domain.ArbitrageOpportunity) (domain.Decimal, error) {
+	buyX, err := s.exchanges.Get(o.BuyExchange)
+	if err != nil {
+		return domain.Decimal{}, err
+	}
+	sellX, err := s.exchanges.Get(o.SellExchange)
+	if err != nil {
+		return domain.Decimal{}, err
+	}
+	acctCtx := ctx
+	if s.cfg.Account != "" {
+		acctCtx = WithAccount(ctx, s.cfg.Account)
+	}
+
+	qty := o.Quantity
+	quote, err := s.freeBalance(acctCtx, buyX, o.Symbol.Quote)
+	if err != nil {
+		return domain.Decimal{}, err
+	}
+	unitCost := o.BuyPrice.Mul(domain.NewDecimalFromInt(1).Add(buyX.TakerFee))
+	if !unitCost.IsPositive() {
+		return domain.Decimal{}, fmt.Errorf("invalid buy price %s", o.BuyPrice)
+	}
+	qty = domain.MinDecimal(qty, quote.Div(unitCost, routeQuantityScale, domain.RoundDown))
+	base, err := s.freeBalance(acctCtx, sellX, o.Symbol.Base)
+	if err != nil {
+		return domain.Decimal{}, err
+	}
+	qty = domain.MinDecimal(qty, base)
+
+	for _, x := range []*Exchange{buyX, sellX} {
+		m, err := x.Markets.Market(ctx, o.Symbol)
+		if err != nil {
+			return domain.Decimal{}, err
+		}
+		if m.StepSize.IsPositive() {
+			qty = qty.RoundToStep(m.StepSize, domain.RoundDown)
+		}
+	}
+	if !qty.IsPositive() {
+		return domain.Decimal{}, errors.New("no balance to trade")
+	}
+	return qty, nil
+}
+
+func (s *ArbitrageScanner) freeBalance(ctx context.Context, x *Exchange, asset string) (domain.Decimal, error) {
+	a, err := x.account(ctx)
+	if err != nil {
+		return domain.Decimal{}, err
+	}
+	balances, err := a.API.GetBalance(ctx)
+	if err != nil {
+		return domain.Decimal{}, fmt.Errorf("%s balance: %w", x.Name, err)
+	}
+	for _, b := range balances {
+		if b.Asset == asset {
+			return b.Free, nil
+		}
+	}
+	return domain.Decimal{}, nil
+}
+
+// arbitrageKey identifies an opportunity across scans.
+func arbitrageKey(o domain.ArbitrageOpportunity) string {
+	return o.Symbol.String() + "/" + o.BuyExchange + "/" + o.SellExchange
+}
@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+var errBatchAborted = errors.New("not placed: another order in the batch failed")
+
+// CreateOrders places a batch of orders with bounded concurrency and
+// reports the outcome of each. With allOrNothing the whole batch is
+// validated before anything is sent, placement stops at the first failure
+// and orders already placed are cancelled again.
+func (s *TradingService) CreateOrders(ctx context.Context, reqs []domain.OrderRequest, allOrNothing bool) []domain.PlacementResult {
+	results := make([]domain.PlacementResult, len(reqs))
+	for i := range results {
+		results[i].Index = i
+	}
+	x, a, err := s.exchanges.ResolveAccount(ctx)
+	if err != nil {
+		for i := range results {
+			results[i].Error = err.Error()
+		}
+		return results
+	}
+
+	normalized := make([]domain.OrderRequest, len(reqs))
+	invalid := false
+	for i, req := range reqs {
+		n, err := x.Markets.Normalize(ctx, req)
+		if err != nil {
+			results[i].Error = err.Error()
+			invalid = true
+			continue
+		}
+		normalized[i] = n
+	}
+	if invalid && allOrNothing {
+		for i := range results {
+			if results[i].Error == "" {
+				results[i].Error = errBatchAborted.Error()
+			}
+		}
+		s.log.Error(ctx, "CreateOrders rejected", ports.Fields{"account": a.Name, "count": len(reqs)})
+		return results
+	}
+
+	var failed atomic.Bool
+	forEachLimit(len(reqs), s.cfg.BatchConcurrency, func(i int) {
+		if results[i].Error != "" {
+			return
+		}
+		if allOrNothing && failed.Load() {
+			results[i].Error = errBatchAborted.Error()
+			return
+		}
+		resp, err := s.placeOrder(ctx, x, a, normalized[i])
+		if err != nil {
+			failed.Store(true)
+			results[i].Error = err.Error()
+			return
+		}
+		resp = a.tagOrder(a.orders.observe(ctx, resp))
+		results[i].Order = &resp
+	})
+
+	if allOrNothing && failed.Load() {
+		s.rollback(ctx, results)
+	}
+
+	s.log.Info(ctx, "CreateOrders done", ports.Fields{"account": a.Name, "count": len(reqs), "failed": failed.Load()})
+	return results
+}
+
+// rollback cancels the placed orders of a failed all-or-nothing batch.
+// Orders that cannot be cancelled keep RolledBack unset.
+func (s *TradingService) rollback(ctx context.Context, results []domain.PlacementResult) {
+	forEachLimit(len(results), s.cfg.CancelConcurrency, func(i int) {
+		o := results[i].Order
+		if o == nil || o.Status.IsFinal() {
+			return
+		}
+		if err := s.CancelOrder(ctx, o.Symbol, o.ID); err != nil {
+			s.log.Error(ctx, "batch rollback: cancel failed", ports.Fields{"orderID": o.ID, "error": err})
+			return
+		}
+		results[i].RolledBack = true
+	})
+}
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// CancelAll cancels every open order, or only those on symbol when it is
+// set, and reports the outcome per order. Pending emulated orders are
+// cancelled first, waiting out any whose trigger is firing, so that no
+// child order is placed once the sweep is done. Exchange orders are then
+// cancelled with the exchange's bulk cancel when available and otherwise
+// one by one.
+func (s *TradingService) CancelAll(ctx context.Context, symbol *domain.Symbol) ([]domain.CancelResult, error) {
+	_, a, err := s.exchanges.ResolveAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer a.balances.invalidate()
+
+	pending := a.Stops.Open(symbol)
+	emulated := make([]domain.CancelResult, len(pending))
+	forEachLimit(len(pending), s.cfg.CancelConcurrency, func(i int) {
+		emulated[i] = cancelResult(pending[i], a.Stops.CancelWait(ctx, pending[i].ID))
+	})
+
+	var results []domain.CancelResult
+	if bulk, ok := a.API.(domain.BulkCanceler); ok {
+		results, err = bulk.CancelAll(ctx, symbol)
+		if err != nil {
+			s.log.Error(ctx, "bulk cancel failed, cancelling individually", ports.Fields{"account": a.Name, "symbol": symbol, "error": err})
+		}
+	}
+	if results == nil {
+		results, err = s.cancelEach(ctx, a, symbol)
+		if err != nil {
+			s.log.Error(ctx, "CancelAll failed", ports.Fields{"account": a.Name, "symbol": symbol, "error": err})
+			return nil, fmt.Errorf("CancelAll failed: %w", err)
+		}
+	}
+
+	results = append(results, emulated...)
+	for i := range results {
+		results[i].Account = a.Name
+	}
+
+	s.log.Info(ctx, "CancelAll done", ports.Fields{"account": a.Name, "symbol": symbol, "count": len(results)})
+	return results, nil
+}
+
+// cancelEach lists the open exchange orders and cancels them with bounded
+// concurrency.
+func (s *TradingService) cancelEach(ctx context.Context, a *Account, symbol *domain.Symbol) ([]domain.CancelResult, error) {
+	open, err := a.API.ListOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]domain.CancelResult, len(open))
+	forEachLimit(len(open), s.cfg.CancelConcurrency, func(i int) {
+		o := open[i]
+		results[i] = cancelResult(o, a.API.CancelOrder(ctx, o.Symbol, o.ID))
+	})
+	return results, nil
+}
+
+func cancelResult(o domain.OrderResponse, err error) domain.CancelResult {
+	r := domain.CancelResult{OrderID: o.ID, Symbol: o.Symbol, Canceled: err == nil}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
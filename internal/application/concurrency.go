@@ -0,0 +1,22 @@
+package application
+
+import "sync"
+
+// forEachLimit calls fn(i) for every i in [0, n) using at most limit
+// goroutines and waits for all calls to return.
+func forEachLimit(n, limit int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,196 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"trade/internal/domain"
+	"trade/internal/orderbook"
+	"trade/internal/ports"
+)
+
+var (
+	// ErrUnknownExchange is returned when a request selects an exchange
+	// that is not configured.
+	ErrUnknownExchange = errors.New("unknown exchange")
+	// ErrUnknownAccount is returned when a request selects an account the
+	// exchange does not have.
+	ErrUnknownAccount = errors.New("unknown account")
+	// ErrAccountRequired is returned for account calls that do not select
+	// an account on an exchange with more than one.
+	ErrAccountRequired = errors.New("account required")
+)
+
+// Exchange is one named exchange connection and the services built on it.
+// API serves public market data; private calls go through an Account.
+// TakerFee is the fraction of a taker fill's value charged as a fee, used
+// to compare exchanges when routing.
+type Exchange struct {
+	Name     string
+	API      domain.ExchangePort
+	Market   domain.MarketStreamPort
+	Markets  *MarketRegistry
+	Books    *orderbook.Manager
+	Accounts []*Account
+	TakerFee domain.Decimal
+}
+
+// Account is one set of credentials on an exchange and the services acting
+// on its behalf.
+type Account struct {
+	Name  string
+	API   domain.ExchangePort
+	User  domain.UserStreamPort
+	Stops *StopOrderManager
+
+	orders   *orderTracker
+	balances *balanceCache
+}
+
+// ExchangeRegistry holds the configured exchanges. Requests pick one by
+// name through their context; those that do not use the default, which
+// is the first one registered.
+type ExchangeRegistry struct {
+	byName map[string]*Exchange
+	names  []string
+}
+
+func NewExchangeRegistry(exchanges []*Exchange, log ports.LoggerPort) (*ExchangeRegistry, error) {
+	if len(exchanges) == 0 {
+		return nil, errors.New("no exchanges configured")
+	}
+	r := &ExchangeRegistry{byName: make(map[string]*Exchange, len(exchanges))}
+	for _, x := range exchanges {
+		if _, dup := r.byName[x.Name]; dup {
+			return nil, fmt.Errorf("exchange %q configured twice", x.Name)
+		}
+		if len(x.Accounts) == 0 {
+			return nil, fmt.Errorf("exchange %q has no accounts", x.Name)
+		}
+		seen := make(map[string]bool)
+		for _, a := range x.Accounts {
+			if seen[a.Name] {
+				return nil, fmt.Errorf("exchange %q: account %q configured twice", x.Name, a.Name)
+			}
+			seen[a.Name] = true
+			a.orders = newOrderTracker(log)
+		}
+		r.byName[x.Name] = x
+		r.names = append(r.names, x.Name)
+	}
+	return r, nil
+}
+
+// Names lists the configured exchanges, default first.
+func (r *ExchangeRegistry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// All returns the configured exchanges, default first.
+func (r *ExchangeRegistry) All() []*Exchange {
+	out := make([]*Exchange, len(r.names))
+	for i, name := range r.names {
+		out[i] = r.byName[name]
+	}
+	return out
+}
+
+func (r *ExchangeRegistry) Get(name string) (*Exchange, error) {
+	x, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExchange, name)
+	}
+	return x, nil
+}
+
+// Resolve returns the exchange selected by ctx, or the default.
+func (r *ExchangeRegistry) Resolve(ctx context.Context) (*Exchange, error) {
+	if name, ok := ExchangeFromContext(ctx); ok {
+		return r.Get(name)
+	}
+	return r.byName[r.names[0]], nil
+}
+
+// ResolveAccount returns the exchange selected by ctx and the account on
+// it selected by ctx. An exchange with a single account does not need one
+// selected.
+func (r *ExchangeRegistry) ResolveAccount(ctx context.Context) (*Exchange, *Account, error) {
+	x, err := r.Resolve(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := x.account(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, a, nil
+}
+
+// account returns the account of x selected by ctx, or its only account.
+func (x *Exchange) account(ctx context.Context) (*Account, error) {
+	name, ok := AccountFromContext(ctx)
+	if !ok {
+		if len(x.Accounts) > 1 {
+			return nil, fmt.Errorf("%w: %s has several accounts", ErrAccountRequired, x.Name)
+		}
+		return x.Accounts[0], nil
+	}
+	for _, a := range x.Accounts {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q on %s", ErrUnknownAccount, name, x.Name)
+}
+
+// tagOrder marks o and its children as belonging to a.
+func (a *Account) tagOrder(o domain.OrderResponse) domain.OrderResponse {
+	o.Account = a.Name
+	if len(o.Children) > 0 {
+		children := make([]domain.OrderResponse, len(o.Children))
+		for i, c := range o.Children {
+			children[i] = a.tagOrder(c)
+		}
+		o.Children = children
+	}
+	return o
+}
+
+// AccountNames lists the accounts of x.
+func (x *Exchange) AccountNames() []string {
+	names := make([]string, len(x.Accounts))
+	for i, a := range x.Accounts {
+		names[i] = a.Name
+	}
+	return names
+}
+
+type (
+	exchangeKey struct{}
+	accountKey  struct{}
+)
+
+// WithExchange selects the exchange named name for calls made with the
+// returned context.
+func WithExchange(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, exchangeKey{}, name)
+}
+
+// ExchangeFromContext returns the exchange selected with WithExchange.
+func ExchangeFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(exchangeKey{}).(string)
+	return name, ok
+}
+
+// WithAccount selects the account named name, on the selected exchange,
+// for calls made with the returned context.
+func WithAccount(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, accountKey{}, name)
+}
+
+// AccountFromContext returns the account selected with WithAccount.
+func AccountFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(accountKey{}).(string)
+	return name, ok
+}
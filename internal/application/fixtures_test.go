@@ -0,0 +1,64 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"trade/internal/adapters/fake"
+	"trade/internal/domain"
+	"trade/internal/orderbook"
+	"trade/internal/ports"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(context.Context, string, ports.Fields)  {}
+func (nopLogger) Error(context.Context, string, ports.Fields) {}
+func (nopLogger) Debug(context.Context, string, ports.Fields) {}
+
+var btcIRT = domain.NewSymbol("BTC", "IRT")
+
+func dec(s string) domain.Decimal { return domain.MustParseDecimal(s) }
+
+func decp(s string) *domain.Decimal {
+	d := dec(s)
+	return &d
+}
+
+func lvl(price, qty string) domain.DepthLevel {
+	return domain.DepthLevel{Price: dec(price), Quantity: dec(qty)}
+}
+
+// newTestVenue builds an exchange with one account on a fake that lists
+// BTC-IRT with a 99/101 book and holds 1,000,000 IRT and 100 BTC. The
+// stop watcher never polls on its own.
+func newTestVenue(t *testing.T, name string) (*fake.Exchange, *Exchange, *Account) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	log := nopLogger{}
+
+	f := fake.NewExchange()
+	f.AddMarket(domain.Market{Symbol: btcIRT, TickSize: dec("1"), StepSize: dec("0.001"), Tradable: true})
+	f.SetBook(domain.OrderBook{Symbol: btcIRT, Bids: []domain.DepthLevel{lvl("99", "10")}, Asks: []domain.DepthLevel{lvl("101", "10")}})
+	f.SetBalances(domain.Balance{Asset: "IRT", Free: dec("1000000")}, domain.Balance{Asset: "BTC", Free: dec("100")})
+
+	books := orderbook.NewManager(ctx, f, f, time.Minute, log)
+	a := &Account{
+		Name:     "main",
+		API:      f,
+		Stops:    NewStopOrderManager(ctx, f, books, time.Hour, log),
+		orders:   newOrderTracker(log),
+		balances: newBalanceCache(f, time.Minute),
+	}
+	x := &Exchange{
+		Name:     name,
+		API:      f,
+		Market:   f,
+		Markets:  NewMarketRegistry(f, time.Minute, false, log),
+		Books:    books,
+		Accounts: []*Account{a},
+	}
+	return f, x, a
+}
@@ -0,0 +1,144 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+)
+
+// balanceCache holds an account's balances for a short time so that
+// pre-trade checks do not fetch them for every order. Orders placed in
+// the meantime move their cost from free to locked, and cancels drop the
+// cache, so back-to-back orders are checked against what is left.
+type balanceCache struct {
+	api domain.ExchangePort
+	ttl time.Duration
+
+	mu       sync.Mutex
+	balances map[string]domain.Balance
+	fetched  time.Time
+}
+
+func newBalanceCache(api domain.ExchangePort, ttl time.Duration) *balanceCache {
+	return &balanceCache{api: api, ttl: ttl}
+}
+
+// get returns the cached balances, fetching them once they are older
+// than the TTL.
+func (c *balanceCache) get(ctx context.Context) (map[string]domain.Balance, error) {
+	c.mu.Lock()
+	if c.balances != nil && time.Since(c.fetched) < c.ttl {
+		out := c.copyLocked()
+		c.mu.Unlock()
+		return out, nil
+	}
+	c.mu.Unlock()
+
+	list, err := c.api.GetBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.store(list)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.copyLocked(), nil
+}
+
+// store replaces the cache with freshly fetched balances.
+func (c *balanceCache) store(list []domain.Balance) {
+	balances := make(map[string]domain.Balance, len(list))
+	for _, b := range list {
+		balances[b.Asset] = b
+	}
+	c.mu.Lock()
+	c.balances, c.fetched = balances, time.Now()
+	c.mu.Unlock()
+}
+
+// reserve moves amount of asset from free to locked until the next fetch.
+func (c *balanceCache) reserve(asset string, amount domain.Decimal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.balances == nil {
+		return
+	}
+	b := c.balances[asset]
+	b.Asset = asset
+	b.Free = b.Free.Sub(amount)
+	b.Locked = b.Locked.Add(amount)
+	c.balances[asset] = b
+}
+
+// invalidate drops the cache, e.g. after a cancel released funds.
+func (c *balanceCache) invalidate() {
+	c.mu.Lock()
+	c.balances = nil
+	c.mu.Unlock()
+}
+
+func (c *balanceCache) copyLocked() map[string]domain.Balance {
+	out := make(map[string]domain.Balance, len(c.balances))
+	for k, v := range c.balances {
+		out[k] = v
+	}
+	return out
+}
+
+// requiredFunds returns the asset and amount req locks when placed: the
+// base quantity for sells and the quote cost for buys, including the
+// exchange's taker fee. Market buys are costed by walking the book; it
+// reports false when the cost cannot be estimated.
+func (s *TradingService) requiredFunds(ctx context.Context, x *Exchange, req domain.OrderRequest) (string, domain.Decimal, bool, error) {
+	if req.Side == domain.SideSell {
+		return req.Symbol.Base, req.Quantity, true, nil
+	}
+	withFee := domain.NewDecimalFromInt(1).Add(x.TakerFee)
+	price := req.Price
+	if price == nil {
+		price = req.TriggerPrice
+	}
+	if price != nil {
+		return req.Symbol.Quote, req.Quantity.Mul(*price).Mul(withFee), true, nil
+	}
+	book, err := x.Books.OrderBook(ctx, req.Symbol)
+	if err != nil {
+		return "", domain.Decimal{}, false, fmt.Errorf("funds check: %w", err)
+	}
+	cost, ok := book.CostToFill(req.Side, req.Quantity)
+	return req.Symbol.Quote, cost.Mul(withFee), ok, nil
+}
+
+// checkFunds fails fast when a does not hold the free balance req needs,
+// going by balances cached for ServiceConfig.BalanceTTL.
+func (s *TradingService) checkFunds(ctx context.Context, x *Exchange, a *Account, req domain.OrderRequest) error {
+	if !s.cfg.CheckFunds {
+		return nil
+	}
+	asset, need, ok, err := s.requiredFunds(ctx, x, req)
+	if err != nil || !ok {
+		return err
+	}
+	balances, err := a.balances.get(ctx)
+	if err != nil {
+		return fmt.Errorf("funds check: %w", err)
+	}
+	if free := balances[asset].Free; free.LessThan(need) {
+		return &domain.RiskError{Rule: domain.RiskInsufficientFunds, Symbol: req.Symbol, Asset: asset, Value: need, Limit: free}
+	}
+	return nil
+}
+
+// reserveFunds records what a placed order locks. Emulated stops lock
+// nothing until they fire.
+func (s *TradingService) reserveFunds(ctx context.Context, x *Exchange, a *Account, req domain.OrderRequest, placed domain.OrderResponse) {
+	if !s.cfg.CheckFunds || (a.Stops.Owns(placed.ID) && req.Type != domain.TypeOCO) {
+		return
+	}
+	if asset, amount, ok, err := s.requiredFunds(ctx, x, req); err == nil && ok {
+		a.balances.reserve(asset, amount)
+	}
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"trade/internal/domain"
+)
+
+func TestCheckFundsIncludesTakerFee(t *testing.T) {
+	limit := domain.OrderRequest{Symbol: btcIRT, Side: domain.SideBuy, Type: domain.TypeLimit, Quantity: dec("10"), Price: decp("100")}
+	market := domain.OrderRequest{Symbol: btcIRT, Side: domain.SideBuy, Type: domain.TypeMarket, Quantity: dec("10")}
+	sell := domain.OrderRequest{Symbol: btcIRT, Side: domain.SideSell, Type: domain.TypeMarket, Quantity: dec("10")}
+
+	// With a 1% fee, 10 BTC at 100 costs 1010 IRT; at the ask of 101, 1020.1.
+	tests := []struct {
+		name string
+		irt  string
+		req  domain.OrderRequest
+		ok   bool
+	}{
+		{"limit covered", "1010", limit, true},
+		{"limit short by the fee", "1009", limit, false},
+		{"market covered", "1020.1", market, true},
+		{"market short by the fee", "1020", market, false},
+		{"sell pays no fee up front", "0", sell, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, x, a := newTestVenue(t, "alpha")
+			x.TakerFee = dec("0.01")
+			f.SetBalances(domain.Balance{Asset: "IRT", Free: dec(tt.irt)}, domain.Balance{Asset: "BTC", Free: dec("10")})
+			svc := &TradingService{cfg: ServiceConfig{CheckFunds: true}}
+
+			err := svc.checkFunds(context.Background(), x, a, tt.req)
+			var rerr *domain.RiskError
+			switch {
+			case tt.ok && err != nil:
+				t.Errorf("err = %v, want none", err)
+			case !tt.ok && (!errors.As(err, &rerr) || rerr.Rule != domain.RiskInsufficientFunds):
+				t.Errorf("err = %v, want %s", err, domain.RiskInsufficientFunds)
+			}
+		})
+	}
+}
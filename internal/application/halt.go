@@ -0,0 +1,136 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// ErrTradingHalted is returned for new orders while the kill switch is on.
+var ErrTradingHalted = errors.New("trading halted")
+
+// haltByConfig is recorded as the origin of a halt set by configuration.
+const haltByConfig = "config"
+
+// initHalt sets the starting halt state: the saved one if any, halted
+// when configured so, and halted when the saved state cannot be read.
+func (s *TradingService) initHalt(ctx context.Context) {
+	state := domain.HaltState{Since: time.Now()}
+	if s.cfg.HaltStore != nil {
+		saved, ok, err := s.cfg.HaltStore.LoadHalt(ctx)
+		switch {
+		case err != nil:
+			s.log.Error(ctx, "halt state unreadable, starting halted", ports.Fields{"error": err})
+			state = domain.HaltState{Halted: true, Reason: "saved halt state unreadable", By: haltByConfig, Since: time.Now()}
+		case ok:
+			state = saved
+		}
+	}
+	if s.cfg.Halted && !state.Halted {
+		state = domain.HaltState{Halted: true, Reason: "TRADING_HALTED is set", By: haltByConfig, Since: time.Now()}
+	}
+	s.applyHalt(state)
+	if state.Halted {
+		s.log.Info(ctx, "trading halted at startup", ports.Fields{"by": state.By, "reason": state.Reason, "since": state.Since})
+	}
+}
+
+// HaltState returns the kill switch state.
+func (s *TradingService) HaltState() domain.HaltState {
+	s.haltMu.RLock()
+	defer s.haltMu.RUnlock()
+	return s.halt
+}
+
+// SetHalt turns the kill switch on or off on behalf of by and saves the
+// new state. The state takes effect even if saving it fails, which is
+// reported as an error. The lock is held until the state is saved, so
+// that concurrent calls save their states in the order they applied them.
+func (s *TradingService) SetHalt(ctx context.Context, halted bool, by, reason string) (domain.HaltState, error) {
+	state := domain.HaltState{Halted: halted, Reason: reason, By: by, Since: time.Now()}
+	s.haltMu.Lock()
+	defer s.haltMu.Unlock()
+	s.applyHaltLocked(state)
+	msg := "trading resumed"
+	if halted {
+		msg = "trading halted"
+	}
+	s.log.Info(ctx, msg, ports.Fields{"by": by, "reason": reason})
+
+	if s.cfg.HaltStore != nil {
+		if err := s.cfg.HaltStore.SaveHalt(ctx, state); err != nil {
+			s.log.Error(ctx, "SetHalt: halt state not saved", ports.Fields{"by": by, "error": err})
+			return state, fmt.Errorf("SetHalt: state applied but not saved: %w", err)
+		}
+	}
+	return state, nil
+}
+
+// applyHalt switches the service and every emulated-order watcher.
+func (s *TradingService) applyHalt(state domain.HaltState) {
+	s.haltMu.Lock()
+	defer s.haltMu.Unlock()
+	s.applyHaltLocked(state)
+}
+
+// applyHaltLocked is applyHalt for callers holding haltMu.
+func (s *TradingService) applyHaltLocked(state domain.HaltState) {
+	s.halt = state
+	for _, x := range s.exchanges.All() {
+		for _, a := range x.Accounts {
+			if a.Stops != nil {
+				a.Stops.Hold(state.Halted)
+			}
+		}
+	}
+}
+
+// checkHalt rejects new orders while trading is halted.
+func (s *TradingService) checkHalt() error {
+	if state := s.HaltState(); state.Halted {
+		if state.Reason != "" {
+			return fmt.Errorf("%w: %s", ErrTradingHalted, state.Reason)
+		}
+		return ErrTradingHalted
+	}
+	return nil
+}
+
+// CancelEverything cancels the open orders of every account on every
+// exchange, emulated ones included, and reports the outcome per order.
+func (s *TradingService) CancelEverything(ctx context.Context) []domain.CancelResult {
+	type target struct {
+		x *Exchange
+		a *Account
+	}
+	var targets []target
+	for _, x := range s.exchanges.All() {
+		for _, a := range x.Accounts {
+			targets = append(targets, target{x, a})
+		}
+	}
+
+	found := make([][]domain.CancelResult, len(targets))
+	forEachLimit(len(targets), len(targets), func(i int) {
+		t := targets[i]
+		results, err := s.CancelAll(WithAccount(WithExchange(ctx, t.x.Name), t.a.Name), nil)
+		if err != nil {
+			results = []domain.CancelResult{{Error: err.Error(), Account: t.a.Name}}
+		}
+		for j := range results {
+			results[j].Exchange = t.x.Name
+		}
+		found[i] = results
+	})
+
+	var out []domain.CancelResult
+	for _, results := range found {
+		out = append(out, results...)
+	}
+	s.log.Info(ctx, "CancelEverything done", ports.Fields{"accounts": len(targets), "count": len(out)})
+	return out
+}
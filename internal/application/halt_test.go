@@ -0,0 +1,57 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"trade/internal/domain"
+)
+
+// memHaltStore keeps every state saved, in order.
+type memHaltStore struct {
+	mu    sync.Mutex
+	saved []domain.HaltState
+}
+
+func (s *memHaltStore) LoadHalt(context.Context) (domain.HaltState, bool, error) {
+	return domain.HaltState{}, false, nil
+}
+
+func (s *memHaltStore) SaveHalt(_ context.Context, state domain.HaltState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = append(s.saved, state)
+	return nil
+}
+
+func TestSetHaltSavesTheStateInEffect(t *testing.T) {
+	_, x, _ := newTestVenue(t, "alpha")
+	registry, err := NewExchangeRegistry([]*Exchange{x}, nopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memHaltStore{}
+	svc := NewTradingService(registry, ServiceConfig{HaltStore: store}, nopLogger{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := svc.SetHalt(context.Background(), i%2 == 0, "test", fmt.Sprint(i)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	last := store.saved[len(store.saved)-1]
+	if got := svc.HaltState(); got != last {
+		t.Errorf("in effect %+v, last saved %+v", got, last)
+	}
+	if halted := svc.checkHalt() != nil; halted != last.Halted {
+		t.Errorf("checkHalt disagrees with the saved state %+v", last)
+	}
+}
@@ -0,0 +1,230 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// MarketRegistry caches an exchange's market rules and checks orders
+// against them before they are sent.
+type MarketRegistry struct {
+	exchange domain.ExchangePort
+	ttl      time.Duration
+	strict   bool
+	log      ports.LoggerPort
+
+	mu      sync.RWMutex
+	markets map[domain.Symbol]domain.Market
+	fetched time.Time
+}
+
+// NewMarketRegistry returns a registry that refreshes its cache after ttl.
+// When strict is set, prices and quantities off the tick or step grid are
+// rejected instead of rounded.
+func NewMarketRegistry(exch domain.ExchangePort, ttl time.Duration, strict bool, log ports.LoggerPort) *MarketRegistry {
+	return &MarketRegistry{exchange: exch, ttl: ttl, strict: strict, log: log}
+}
+
+func (r *MarketRegistry) Markets(ctx context.Context) ([]domain.Market, error) {
+	if err := r.refresh(ctx); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.Market, 0, len(r.markets))
+	for _, m := range r.markets {
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func (r *MarketRegistry) Market(ctx context.Context, symbol domain.Symbol) (domain.Market, error) {
+	if err := r.refresh(ctx); err != nil {
+		return domain.Market{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.markets[symbol]
+	if !ok {
+		return domain.Market{}, &domain.OrderValidationError{Rule: domain.RuleUnknownMarket, Field: "Symbol", Symbol: symbol}
+	}
+	return m, nil
+}
+
+func (r *MarketRegistry) refresh(ctx context.Context) error {
+	r.mu.RLock()
+	fresh := r.markets != nil && time.Since(r.fetched) < r.ttl
+	r.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	list, err := r.exchange.GetMarkets(ctx)
+	if err != nil {
+		r.mu.RLock()
+		stale := r.markets != nil
+		r.mu.RUnlock()
+		if stale {
+			r.log.Error(ctx, "market refresh failed, using cached markets", ports.Fields{"error": err})
+			return nil
+		}
+		return fmt.Errorf("load markets: %w", err)
+	}
+
+	markets := make(map[domain.Symbol]domain.Market, len(list))
+	for _, m := range list {
+		markets[m.Symbol] = m
+	}
+	r.mu.Lock()
+	r.markets = markets
+	r.fetched = time.Now()
+	r.mu.Unlock()
+	r.log.Debug(ctx, "markets refreshed", ports.Fields{"count": len(markets)})
+	return nil
+}
+
+// Normalize snaps the order's prices to the tick size and its quantity to
+// the step size, then checks the market minimums. Rounding never works
+// against the caller: quantities round down, and limit prices, including
+// an OCO's StopLimitPrice, round down for buys and up for sells. Trigger
+// prices are only a threshold and round to the nearest tick.
+func (r *MarketRegistry) Normalize(ctx context.Context, req domain.OrderRequest) (domain.OrderRequest, error) {
+	m, err := r.Market(ctx, req.Symbol)
+	if err != nil {
+		return req, err
+	}
+	if !m.Tradable {
+		return req, &domain.OrderValidationError{Rule: domain.RuleMarketClosed, Field: "Symbol", Symbol: req.Symbol}
+	}
+
+	needPrice, needTrigger, err := priceRequirements(req)
+	if err != nil {
+		return req, err
+	}
+	if err := checkOrderFields(req); err != nil {
+		return req, err
+	}
+	if err := checkOrderOptions(req); err != nil {
+		return req, err
+	}
+	if needPrice && req.Price == nil {
+		return req, &domain.OrderValidationError{Rule: domain.RulePriceRequired, Field: "Price", Symbol: req.Symbol}
+	}
+	if needTrigger && req.TriggerPrice == nil {
+		return req, &domain.OrderValidationError{Rule: domain.RulePriceRequired, Field: "TriggerPrice", Symbol: req.Symbol}
+	}
+
+	limitMode := domain.RoundFloor
+	if req.Side == domain.SideSell {
+		limitMode = domain.RoundCeiling
+	}
+	if req.Price, err = r.snapPrice(m, req, "Price", req.Price, limitMode); err != nil {
+		return req, err
+	}
+	if req.TriggerPrice, err = r.snapPrice(m, req, "TriggerPrice", req.TriggerPrice, domain.RoundHalfUp); err != nil {
+		return req, err
+	}
+	if req.StopLimitPrice, err = r.snapPrice(m, req, "StopLimitPrice", req.StopLimitPrice, limitMode); err != nil {
+		return req, err
+	}
+
+	if m.StepSize.IsPositive() && !req.Quantity.IsMultipleOf(m.StepSize) {
+		if r.strict {
+			return req, &domain.OrderValidationError{Rule: domain.RuleLotSize, Field: "Quantity", Symbol: req.Symbol, Value: req.Quantity, Limit: m.StepSize}
+		}
+		req.Quantity = req.Quantity.RoundToStep(m.StepSize, domain.RoundDown)
+	}
+
+	minQty := domain.MaxDecimal(m.MinQuantity, m.StepSize)
+	if !req.Quantity.IsPositive() || req.Quantity.LessThan(minQty) {
+		return req, &domain.OrderValidationError{Rule: domain.RuleMinQuantity, Field: "Quantity", Symbol: req.Symbol, Value: req.Quantity, Limit: minQty}
+	}
+
+	refPrice := req.Price
+	if refPrice == nil {
+		refPrice = req.TriggerPrice
+	}
+	if refPrice != nil && m.MinNotional.IsPositive() {
+		notional := req.Quantity.Mul(*refPrice)
+		if notional.LessThan(m.MinNotional) {
+			return req, &domain.OrderValidationError{Rule: domain.RuleMinNotional, Field: "Notional", Symbol: req.Symbol, Value: notional, Limit: m.MinNotional}
+		}
+	}
+
+	return req, nil
+}
+
+// priceRequirements reports which prices an order type needs.
+func priceRequirements(req domain.OrderRequest) (price, trigger bool, err error) {
+	switch req.Type {
+	case domain.TypeMarket:
+		return false, false, nil
+	case domain.TypeLimit:
+		return true, false, nil
+	case domain.TypeStopMarket:
+		return false, true, nil
+	case domain.TypeStopLimit, domain.TypeOCO:
+		return true, true, nil
+	}
+	return false, false, &domain.OrderValidationError{Rule: domain.RuleOrderType, Field: "Type", Symbol: req.Symbol}
+}
+
+// checkOrderFields rejects unknown sides and prices that are not
+// positive, which would otherwise pass the tick check.
+func checkOrderFields(req domain.OrderRequest) error {
+	if req.Side != domain.SideBuy && req.Side != domain.SideSell {
+		return &domain.OrderValidationError{Rule: domain.RuleSide, Field: "Side", Symbol: req.Symbol}
+	}
+	prices := []struct {
+		field string
+		p     *domain.Decimal
+	}{
+		{"Price", req.Price},
+		{"TriggerPrice", req.TriggerPrice},
+		{"StopLimitPrice", req.StopLimitPrice},
+	}
+	for _, f := range prices {
+		if f.p != nil && !f.p.IsPositive() {
+			return &domain.OrderValidationError{Rule: domain.RulePrice, Field: f.field, Symbol: req.Symbol, Value: *f.p}
+		}
+	}
+	return nil
+}
+
+// checkOrderOptions rejects time-in-force and post-only combinations that
+// cannot be honoured, natively or by emulation.
+func checkOrderOptions(req domain.OrderRequest) error {
+	switch req.TimeInForce {
+	case "", domain.TimeInForceGTC, domain.TimeInForceIOC, domain.TimeInForceFOK:
+	default:
+		return &domain.OrderValidationError{Rule: domain.RuleTimeInForce, Field: "TimeInForce", Symbol: req.Symbol}
+	}
+	if req.Type.IsConditional() && req.TimeInForce.IsImmediate() {
+		return &domain.OrderValidationError{Rule: domain.RuleTimeInForce, Field: "TimeInForce", Symbol: req.Symbol}
+	}
+	if req.PostOnly && (req.Type != domain.TypeLimit || req.TimeInForce.IsImmediate()) {
+		return &domain.OrderValidationError{Rule: domain.RulePostOnly, Field: "PostOnly", Symbol: req.Symbol}
+	}
+	return nil
+}
+
+// snapPrice rounds p to the market's tick size with mode, or rejects it in
+// strict mode.
+func (r *MarketRegistry) snapPrice(m domain.Market, req domain.OrderRequest, field string, p *domain.Decimal, mode domain.RoundingMode) (*domain.Decimal, error) {
+	if p == nil || !m.TickSize.IsPositive() || p.IsMultipleOf(m.TickSize) {
+		return p, nil
+	}
+	if r.strict {
+		return p, &domain.OrderValidationError{Rule: domain.RuleTickSize, Field: field, Symbol: req.Symbol, Value: *p, Limit: m.TickSize}
+	}
+	price := p.RoundToStep(m.TickSize, mode)
+	if !price.IsPositive() {
+		return p, &domain.OrderValidationError{Rule: domain.RuleTickSize, Field: field, Symbol: req.Symbol, Value: *p, Limit: m.TickSize}
+	}
+	return &price, nil
+}
@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade/internal/adapters/fake"
+	"trade/internal/domain"
+)
+
+func TestNormalizePriceRounding(t *testing.T) {
+	f := fake.NewExchange()
+	f.AddMarket(domain.Market{Symbol: btcIRT, TickSize: dec("10"), StepSize: dec("0.001"), Tradable: true})
+	lenient := NewMarketRegistry(f, time.Minute, false, nopLogger{})
+	strict := NewMarketRegistry(f, time.Minute, true, nopLogger{})
+
+	tests := []struct {
+		name          string
+		req           domain.OrderRequest
+		price         string
+		trigger       string
+		stopLimit     string
+		strictRejects string
+	}{
+		{
+			name:          "buy limit rounds down",
+			req:           domain.OrderRequest{Side: domain.SideBuy, Type: domain.TypeLimit, Price: decp("109")},
+			price:         "100",
+			strictRejects: "Price",
+		},
+		{
+			name:          "sell limit rounds up",
+			req:           domain.OrderRequest{Side: domain.SideSell, Type: domain.TypeLimit, Price: decp("101")},
+			price:         "110",
+			strictRejects: "Price",
+		},
+		{
+			name:          "sell trigger rounds to nearest",
+			req:           domain.OrderRequest{Side: domain.SideSell, Type: domain.TypeStopMarket, TriggerPrice: decp("104")},
+			trigger:       "100",
+			strictRejects: "TriggerPrice",
+		},
+		{
+			name:          "buy trigger rounds to nearest",
+			req:           domain.OrderRequest{Side: domain.SideBuy, Type: domain.TypeStopMarket, TriggerPrice: decp("105")},
+			trigger:       "110",
+			strictRejects: "TriggerPrice",
+		},
+		{
+			name:          "OCO stop limit follows the side",
+			req:           domain.OrderRequest{Side: domain.SideSell, Type: domain.TypeOCO, Price: decp("200"), TriggerPrice: decp("100"), StopLimitPrice: decp("91")},
+			price:         "200",
+			trigger:       "100",
+			stopLimit:     "100",
+			strictRejects: "StopLimitPrice",
+		},
+	}
+	check := func(t *testing.T, field string, got *domain.Decimal, want string) {
+		t.Helper()
+		switch {
+		case want == "" && got != nil:
+			t.Errorf("%s = %s, want none", field, got)
+		case want != "" && (got == nil || !got.Equal(dec(want))):
+			t.Errorf("%s = %v, want %s", field, got, want)
+		}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Symbol, req.Quantity = btcIRT, dec("1")
+
+			got, err := lenient.Normalize(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, "Price", got.Price, tt.price)
+			check(t, "TriggerPrice", got.TriggerPrice, tt.trigger)
+			check(t, "StopLimitPrice", got.StopLimitPrice, tt.stopLimit)
+
+			_, err = strict.Normalize(context.Background(), req)
+			var verr *domain.OrderValidationError
+			if !errors.As(err, &verr) || verr.Rule != domain.RuleTickSize || verr.Field != tt.strictRejects {
+				t.Errorf("strict: err = %v, want %s on %s", err, domain.RuleTickSize, tt.strictRejects)
+			}
+		})
+	}
+}
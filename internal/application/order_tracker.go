@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// orderTrackerTTL is how long an order is remembered after it was last
+// seen.
+const orderTrackerTTL = time.Hour
+
+type trackedOrder struct {
+	status domain.OrderStatus
+	seen   time.Time
+}
+
+// orderTracker remembers the last status seen for each order and corrects
+// updates that break the order lifecycle, such as a stale read reporting a
+// filled order as new again; these usually mean the exchange's status
+// mapping needs attention and are logged. Orders are forgotten once they
+// have not been seen for orderTrackerTTL.
+type orderTracker struct {
+	mu    sync.Mutex
+	last  map[string]trackedOrder
+	swept time.Time
+	log   ports.LoggerPort
+}
+
+func newOrderTracker(log ports.LoggerPort) *orderTracker {
+	return &orderTracker{last: make(map[string]trackedOrder), swept: time.Now(), log: log}
+}
+
+// observe records o and returns it with its status corrected: when the
+// status is not a legal successor of the previously observed one, the
+// previous status is kept.
+func (t *orderTracker) observe(ctx context.Context, o domain.OrderResponse) domain.OrderResponse {
+	key := o.Symbol.String() + "/" + o.ID
+	now := time.Now()
+
+	t.mu.Lock()
+	if now.Sub(t.swept) > orderTrackerTTL {
+		for k, v := range t.last {
+			if now.Sub(v.seen) > orderTrackerTTL {
+				delete(t.last, k)
+			}
+		}
+		t.swept = now
+	}
+	prev, seen := t.last[key]
+	legal := !seen || prev.status.CanTransitionTo(o.Status)
+	if legal {
+		t.last[key] = trackedOrder{status: o.Status, seen: now}
+	} else {
+		t.last[key] = trackedOrder{status: prev.status, seen: now}
+	}
+	t.mu.Unlock()
+
+	if !legal {
+		t.log.Error(ctx, "invalid order status transition", ports.Fields{
+			"orderID":   o.ID,
+			"symbol":    o.Symbol,
+			"from":      prev.status,
+			"to":        o.Status,
+			"rawStatus": o.RawStatus,
+		})
+		o.Status = prev.status
+	}
+	return o
+}
@@ -0,0 +1,369 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+)
+
+// RiskRule is one pre-trade check. It returns a *domain.RiskError to stop
+// the order, or another error when it could not decide, which also stops
+// it.
+type RiskRule interface {
+	Check(ctx context.Context, o *RiskOrder) error
+}
+
+// RiskRuleFunc adapts a function to RiskRule.
+type RiskRuleFunc func(ctx context.Context, o *RiskOrder) error
+
+func (f RiskRuleFunc) Check(ctx context.Context, o *RiskOrder) error { return f(ctx, o) }
+
+// RiskEngine runs its rules, in order, on every order before it is sent.
+// The first rule to object stops the order.
+type RiskEngine struct {
+	rules []RiskRule
+}
+
+func NewRiskEngine(rules ...RiskRule) *RiskEngine {
+	return &RiskEngine{rules: rules}
+}
+
+// check runs the rules on req for a on x. A nil engine allows everything.
+func (e *RiskEngine) check(ctx context.Context, x *Exchange, a *Account, req domain.OrderRequest) error {
+	return e.run(ctx, &RiskOrder{Request: req, Exchange: x.Name, Account: a.Name, x: x, a: a})
+}
+
+func (e *RiskEngine) run(ctx context.Context, o *RiskOrder) error {
+	if e == nil || len(e.rules) == 0 {
+		return nil
+	}
+	for _, rule := range e.rules {
+		if err := rule.Check(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RiskOrder is an order under pre-trade check together with the account
+// and market state rules look at. Each lookup is made at most once per
+// check and shared by the rules.
+type RiskOrder struct {
+	Request  domain.OrderRequest
+	Exchange string
+	Account  string
+
+	x    *Exchange
+	a    *Account
+	book *domain.OrderBook
+	open *[]domain.OrderResponse
+	// replaces is the emulated order the request stands in for, which is
+	// not counted as open.
+	replaces string
+}
+
+// Book returns the current book of the order's market.
+func (o *RiskOrder) Book(ctx context.Context) (domain.OrderBook, error) {
+	if o.book == nil {
+		book, err := o.x.Books.OrderBook(ctx, o.Request.Symbol)
+		if err != nil {
+			return domain.OrderBook{}, fmt.Errorf("risk check: %w", err)
+		}
+		o.book = &book
+	}
+	return *o.book, nil
+}
+
+// Balances returns the account's balances by asset, as cached for the
+// funds check.
+func (o *RiskOrder) Balances(ctx context.Context) (map[string]domain.Balance, error) {
+	balances, err := o.a.balances.get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("risk check: %w", err)
+	}
+	return balances, nil
+}
+
+// OpenOrders returns the account's open orders on the order's market,
+// emulated ones included.
+func (o *RiskOrder) OpenOrders(ctx context.Context) ([]domain.OrderResponse, error) {
+	if o.open == nil {
+		symbol := o.Request.Symbol
+		open, err := o.a.API.ListOpenOrders(ctx, &symbol)
+		if err != nil {
+			return nil, fmt.Errorf("risk check: %w", err)
+		}
+		for _, e := range o.a.Stops.Open(&symbol) {
+			if e.ID != o.replaces {
+				open = append(open, e)
+			}
+		}
+		o.open = &open
+	}
+	return *o.open, nil
+}
+
+// ReferencePrice is the price the order is valued at: its limit price,
+// else its trigger price, else the book's mid.
+func (o *RiskOrder) ReferencePrice(ctx context.Context) (domain.Decimal, error) {
+	if o.Request.Price != nil {
+		return *o.Request.Price, nil
+	}
+	if o.Request.TriggerPrice != nil {
+		return *o.Request.TriggerPrice, nil
+	}
+	book, err := o.Book(ctx)
+	if err != nil {
+		return domain.Decimal{}, err
+	}
+	mid, ok := book.Mid()
+	if !ok {
+		return domain.Decimal{}, fmt.Errorf("risk check: no mid price for %s", o.Request.Symbol)
+	}
+	return mid, nil
+}
+
+// riskFractionScale is the precision of fractions compared by rules.
+const riskFractionScale = 8
+
+// tradesPageSize and maxTradePages bound the fills read by the daily loss
+// rule.
+const (
+	tradesPageSize = 200
+	maxTradePages  = 10
+)
+
+// NewMaxNotionalRule caps quantity times reference price per order, with
+// limits keyed by quote asset. Markets whose quote has no limit are not
+// checked.
+func NewMaxNotionalRule(limits map[string]domain.Decimal) RiskRule {
+	return RiskRuleFunc(func(ctx context.Context, o *RiskOrder) error {
+		req := o.Request
+		limit, ok := limits[req.Symbol.Quote]
+		if !ok {
+			return nil
+		}
+		price, err := o.ReferencePrice(ctx)
+		if err != nil {
+			return err
+		}
+		notional := req.Quantity.Mul(price)
+		if notional.GreaterThan(limit) {
+			return &domain.RiskError{Rule: domain.RiskMaxNotional, Symbol: req.Symbol, Asset: req.Symbol.Quote, Value: notional, Limit: limit}
+		}
+		return nil
+	})
+}
+
+// NewMaxPositionRule caps the account's holding of an asset, free and
+// locked, once a buy fills. Limits are keyed by asset; sells are not
+// checked.
+func NewMaxPositionRule(limits map[string]domain.Decimal) RiskRule {
+	return RiskRuleFunc(func(ctx context.Context, o *RiskOrder) error {
+		req := o.Request
+		limit, ok := limits[req.Symbol.Base]
+		if !ok || req.Side != domain.SideBuy {
+			return nil
+		}
+		balances, err := o.Balances(ctx)
+		if err != nil {
+			return err
+		}
+		held := balances[req.Symbol.Base]
+		position := req.Quantity.Add(held.Free).Add(held.Locked)
+		if position.GreaterThan(limit) {
+			return &domain.RiskError{Rule: domain.RiskMaxPosition, Symbol: req.Symbol, Asset: req.Symbol.Base, Value: position, Limit: limit}
+		}
+		return nil
+	})
+}
+
+// NewPriceCollarRule rejects limit prices further than collar, a
+// fraction, from the book's mid. Orders without a limit price are not
+// checked.
+func NewPriceCollarRule(collar domain.Decimal) RiskRule {
+	return RiskRuleFunc(func(ctx context.Context, o *RiskOrder) error {
+		req := o.Request
+		if req.Price == nil {
+			return nil
+		}
+		book, err := o.Book(ctx)
+		if err != nil {
+			return err
+		}
+		mid, ok := book.Mid()
+		if !ok || !mid.IsPositive() {
+			return fmt.Errorf("risk check: no mid price for %s", req.Symbol)
+		}
+		away := req.Price.Sub(mid).Abs().Div(mid, riskFractionScale, domain.RoundHalfEven)
+		if away.GreaterThan(collar) {
+			return &domain.RiskError{Rule: domain.RiskPriceCollar, Symbol: req.Symbol, Value: away, Limit: collar}
+		}
+		return nil
+	})
+}
+
+// NewMaxOpenOrdersRule caps the account's open orders per market.
+func NewMaxOpenOrdersRule(max int) RiskRule {
+	return RiskRuleFunc(func(ctx context.Context, o *RiskOrder) error {
+		open, err := o.OpenOrders(ctx)
+		if err != nil {
+			return err
+		}
+		if len(open) >= max {
+			return &domain.RiskError{Rule: domain.RiskMaxOpenOrders, Symbol: o.Request.Symbol, Value: domain.NewDecimalFromInt(int64(len(open))), Limit: domain.NewDecimalFromInt(int64(max))}
+		}
+		return nil
+	})
+}
+
+// NewDailyLossRule stops trading a quote asset once the account has lost
+// its limit on the day's fills in that quote. The day starts at local
+// midnight; fills are valued as the cash they moved plus the quantity
+// they left, at today's mid. Each account's PnL is reused for
+// dailyPnLTTL, so a loss may go unnoticed for that long.
+func NewDailyLossRule(limits map[string]domain.Decimal) RiskRule {
+	cache := newPnLCache(dailyPnLTTL)
+	return RiskRuleFunc(func(ctx context.Context, o *RiskOrder) error {
+		quote := o.Request.Symbol.Quote
+		limit, ok := limits[quote]
+		if !ok {
+			return nil
+		}
+		pnl, err := cache.get(ctx, o.x, o.a, quote)
+		if err != nil {
+			return err
+		}
+		if loss := pnl.Neg(); loss.Cmp(limit) >= 0 {
+			return &domain.RiskError{Rule: domain.RiskDailyLoss, Symbol: o.Request.Symbol, Asset: quote, Value: loss, Limit: limit}
+		}
+		return nil
+	})
+}
+
+// dailyPnLTTL is how long the daily loss rule reuses a computed PnL.
+const dailyPnLTTL = 10 * time.Second
+
+type pnlKey struct {
+	a     *Account
+	quote string
+}
+
+type pnlEntry struct {
+	pnl      domain.Decimal
+	midnight time.Time
+	fetched  time.Time
+}
+
+// pnlCache holds each account's daily PnL per quote asset for a short
+// time, so that the trade history is not paged through for every order.
+type pnlCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[pnlKey]pnlEntry
+}
+
+func newPnLCache(ttl time.Duration) *pnlCache {
+	return &pnlCache{ttl: ttl, entries: make(map[pnlKey]pnlEntry)}
+}
+
+// get returns the cached PnL of a in quote, computing it again once it is
+// older than the TTL or from a previous day.
+func (c *pnlCache) get(ctx context.Context, x *Exchange, a *Account, quote string) (domain.Decimal, error) {
+	key := pnlKey{a, quote}
+	midnight := localMidnight(time.Now())
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && e.midnight.Equal(midnight) && time.Since(e.fetched) < c.ttl {
+		return e.pnl, nil
+	}
+
+	pnl, err := dailyPnL(ctx, x, a, quote, midnight)
+	if err != nil {
+		return domain.Decimal{}, err
+	}
+	c.mu.Lock()
+	c.entries[key] = pnlEntry{pnl: pnl, midnight: midnight, fetched: time.Now()}
+	c.mu.Unlock()
+	return pnl, nil
+}
+
+func localMidnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// dailyPnL values the fills of a since midnight on markets quoted in
+// quote.
+func dailyPnL(ctx context.Context, x *Exchange, a *Account, quote string, midnight time.Time) (domain.Decimal, error) {
+	cash := domain.Zero
+	held := make(map[domain.Symbol]domain.Decimal)
+	for page := 0; page < maxTradePages; page++ {
+		trades, err := a.API.GetTrades(ctx, domain.HistoryFilter{From: midnight, Limit: tradesPageSize, Offset: page * tradesPageSize})
+		if err != nil {
+			return domain.Decimal{}, fmt.Errorf("risk check: %w", err)
+		}
+		for _, t := range trades {
+			if t.Symbol.Quote != quote || t.Time.Before(midnight) {
+				continue
+			}
+			value := t.Price.Mul(t.Quantity)
+			if t.Side == domain.SideBuy {
+				cash = cash.Sub(value)
+				held[t.Symbol] = held[t.Symbol].Add(t.Quantity)
+			} else {
+				cash = cash.Add(value)
+				held[t.Symbol] = held[t.Symbol].Sub(t.Quantity)
+			}
+			switch t.FeeAsset {
+			case quote:
+				cash = cash.Sub(t.Fee)
+			case t.Symbol.Base:
+				held[t.Symbol] = held[t.Symbol].Sub(t.Fee)
+			}
+		}
+		if len(trades) < tradesPageSize {
+			break
+		}
+	}
+
+	for symbol, qty := range held {
+		if qty.IsZero() {
+			continue
+		}
+		book, err := x.Books.OrderBook(ctx, symbol)
+		if err != nil {
+			return domain.Decimal{}, fmt.Errorf("risk check: %w", err)
+		}
+		mid, ok := book.Mid()
+		if !ok {
+			return domain.Decimal{}, fmt.Errorf("risk check: no mid price for %s", symbol)
+		}
+		cash = cash.Add(qty.Mul(mid))
+	}
+	return cash, nil
+}
+
+// NewSymbolListRule allows only the markets in allow, when it is not
+// empty, and never those in deny.
+func NewSymbolListRule(allow, deny []domain.Symbol) RiskRule {
+	allowed := make(map[domain.Symbol]bool, len(allow))
+	for _, s := range allow {
+		allowed[s] = true
+	}
+	denied := make(map[domain.Symbol]bool, len(deny))
+	for _, s := range deny {
+		denied[s] = true
+	}
+	return RiskRuleFunc(func(ctx context.Context, o *RiskOrder) error {
+		symbol := o.Request.Symbol
+		if denied[symbol] || (len(allowed) > 0 && !allowed[symbol]) {
+			return &domain.RiskError{Rule: domain.RiskSymbolNotAllowed, Symbol: symbol}
+		}
+		return nil
+	})
+}
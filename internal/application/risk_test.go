@@ -0,0 +1,144 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade/internal/adapters/fake"
+	"trade/internal/domain"
+)
+
+func TestRiskRules(t *testing.T) {
+	now := time.Now()
+	market := func(side domain.OrderSide, qty string) domain.OrderRequest {
+		return domain.OrderRequest{Symbol: btcIRT, Side: side, Type: domain.TypeMarket, Quantity: dec(qty)}
+	}
+	limit := func(side domain.OrderSide, qty, price string) domain.OrderRequest {
+		req := market(side, qty)
+		req.Type, req.Price = domain.TypeLimit, decp(price)
+		return req
+	}
+	holding := func(free, locked string) func(*fake.Exchange) {
+		return func(f *fake.Exchange) {
+			f.SetBalances(domain.Balance{Asset: "BTC", Free: dec(free), Locked: dec(locked)})
+		}
+	}
+	openOrders := func(n int) func(*fake.Exchange) {
+		return func(f *fake.Exchange) {
+			for i := 0; i < n; i++ {
+				if _, err := f.CreateOrder(context.Background(), limit(domain.SideBuy, "1", "90")); err != nil {
+					panic(err)
+				}
+			}
+		}
+	}
+	fills := func(trades ...domain.Trade) func(*fake.Exchange) {
+		return func(f *fake.Exchange) { f.SetTrades(trades...) }
+	}
+	buyAt := func(price string, at time.Time) domain.Trade {
+		return domain.Trade{Symbol: btcIRT, Side: domain.SideBuy, Price: dec(price), Quantity: dec("1"), Time: at}
+	}
+	irt := func(s string) map[string]domain.Decimal { return map[string]domain.Decimal{"IRT": dec(s)} }
+	btc := func(s string) map[string]domain.Decimal { return map[string]domain.Decimal{"BTC": dec(s)} }
+	ethIRT := domain.NewSymbol("ETH", "IRT")
+
+	tests := []struct {
+		name  string
+		rule  func() RiskRule
+		setup func(*fake.Exchange)
+		req   domain.OrderRequest
+		want  string
+	}{
+		// Market orders are valued at the mid of 100.
+		{"notional within", func() RiskRule { return NewMaxNotionalRule(irt("1000")) }, nil, market(domain.SideBuy, "9"), ""},
+		{"notional over", func() RiskRule { return NewMaxNotionalRule(irt("1000")) }, nil, market(domain.SideSell, "11"), domain.RiskMaxNotional},
+		{"notional at limit price", func() RiskRule { return NewMaxNotionalRule(irt("1000")) }, nil, limit(domain.SideBuy, "6", "200"), domain.RiskMaxNotional},
+		{"notional other quote", func() RiskRule { return NewMaxNotionalRule(map[string]domain.Decimal{"USDT": dec("1")}) }, nil, market(domain.SideBuy, "9"), ""},
+
+		{"position within", func() RiskRule { return NewMaxPositionRule(btc("5")) }, holding("2", "1"), market(domain.SideBuy, "2"), ""},
+		{"position over", func() RiskRule { return NewMaxPositionRule(btc("5")) }, holding("2", "1"), market(domain.SideBuy, "2.5"), domain.RiskMaxPosition},
+		{"position sell", func() RiskRule { return NewMaxPositionRule(btc("5")) }, holding("20", "0"), market(domain.SideSell, "1"), ""},
+
+		{"collar within", func() RiskRule { return NewPriceCollarRule(dec("0.05")) }, nil, limit(domain.SideSell, "1", "104"), ""},
+		{"collar above", func() RiskRule { return NewPriceCollarRule(dec("0.05")) }, nil, limit(domain.SideSell, "1", "106"), domain.RiskPriceCollar},
+		{"collar below", func() RiskRule { return NewPriceCollarRule(dec("0.05")) }, nil, limit(domain.SideBuy, "1", "94"), domain.RiskPriceCollar},
+		{"collar market", func() RiskRule { return NewPriceCollarRule(dec("0.05")) }, nil, market(domain.SideBuy, "1"), ""},
+
+		{"open orders under", func() RiskRule { return NewMaxOpenOrdersRule(2) }, openOrders(1), market(domain.SideBuy, "1"), ""},
+		{"open orders at max", func() RiskRule { return NewMaxOpenOrdersRule(2) }, openOrders(2), market(domain.SideBuy, "1"), domain.RiskMaxOpenOrders},
+
+		// A coin bought today and now worth the mid of 100.
+		{"daily loss under", func() RiskRule { return NewDailyLossRule(irt("100")) }, fills(buyAt("150", now)), market(domain.SideBuy, "1"), ""},
+		{"daily loss reached", func() RiskRule { return NewDailyLossRule(irt("100")) }, fills(buyAt("200", now)), market(domain.SideBuy, "1"), domain.RiskDailyLoss},
+		{"daily loss yesterday", func() RiskRule { return NewDailyLossRule(irt("100")) }, fills(buyAt("1000", localMidnight(now).Add(-time.Hour))), market(domain.SideBuy, "1"), ""},
+
+		{"symbol allowed", func() RiskRule { return NewSymbolListRule([]domain.Symbol{btcIRT}, nil) }, nil, market(domain.SideBuy, "1"), ""},
+		{"symbol not allowed", func() RiskRule { return NewSymbolListRule([]domain.Symbol{ethIRT}, nil) }, nil, market(domain.SideBuy, "1"), domain.RiskSymbolNotAllowed},
+		{"symbol denied", func() RiskRule { return NewSymbolListRule(nil, []domain.Symbol{btcIRT}) }, nil, market(domain.SideBuy, "1"), domain.RiskSymbolNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, x, a := newTestVenue(t, "alpha")
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			err := NewRiskEngine(tt.rule()).check(context.Background(), x, a, tt.req)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want none", err)
+				}
+				return
+			}
+			var rerr *domain.RiskError
+			if !errors.As(err, &rerr) || rerr.Rule != tt.want {
+				t.Fatalf("err = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRiskOpenOrdersCountEmulatedStops(t *testing.T) {
+	ctx := context.Background()
+	_, x, a := newTestVenue(t, "alpha")
+	stop := domain.OrderRequest{Symbol: btcIRT, Side: domain.SideSell, Type: domain.TypeStopMarket, Quantity: dec("1"), TriggerPrice: decp("50")}
+	placed, err := a.Stops.Submit(ctx, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewRiskEngine(NewMaxOpenOrdersRule(1))
+	var rerr *domain.RiskError
+	if err := engine.check(ctx, x, a, stop); !errors.As(err, &rerr) {
+		t.Fatalf("err = %v, want %s", err, domain.RiskMaxOpenOrders)
+	}
+	// The child of a firing stop replaces it rather than adding to it.
+	o := &RiskOrder{Request: stop, x: x, a: a, replaces: placed.ID}
+	if err := engine.run(ctx, o); err != nil {
+		t.Fatalf("replacing child: err = %v", err)
+	}
+}
+
+func TestRiskEngineStopsAtFirstObjection(t *testing.T) {
+	_, x, a := newTestVenue(t, "alpha")
+	var ran []string
+	rule := func(name string, err error) RiskRule {
+		return RiskRuleFunc(func(context.Context, *RiskOrder) error {
+			ran = append(ran, name)
+			return err
+		})
+	}
+	boom := errors.New("boom")
+	engine := NewRiskEngine(rule("a", nil), rule("b", boom), rule("c", nil))
+	if err := engine.check(context.Background(), x, a, domain.OrderRequest{Symbol: btcIRT}); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+	if len(ran) != 2 {
+		t.Errorf("ran %v, want a and b", ran)
+	}
+
+	var nilEngine *RiskEngine
+	if err := nilEngine.check(context.Background(), x, a, domain.OrderRequest{}); err != nil {
+		t.Errorf("nil engine: err = %v", err)
+	}
+}
@@ -0,0 +1,364 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// routedIDPrefix marks the IDs of routed parent orders, which exist only
+// in the response that placed them.
+const routedIDPrefix = "route-"
+
+// rawRouted is the raw status of a routed parent order.
+const rawRouted = "routed"
+
+// routeQuantityScale is the precision of planned quantities before each
+// exchange snaps them to its step size.
+const routeQuantityScale = 12
+
+type smartRoutingKey struct{}
+
+// WithSmartRouting makes CreateOrder calls made with the returned context
+// split the order across all exchanges instead of placing it on the
+// selected one.
+func WithSmartRouting(ctx context.Context) context.Context {
+	return context.WithValue(ctx, smartRoutingKey{}, true)
+}
+
+func smartRoutingFromContext(ctx context.Context) bool {
+	on, _ := ctx.Value(smartRoutingKey{}).(bool)
+	return on
+}
+
+// venue is one exchange's side of a routing plan.
+type venue struct {
+	x    *Exchange
+	a    *Account
+	book domain.OrderBook
+	// budget is what the account can spend: the free quote balance for
+	// buys and the free base balance for sells.
+	budget   domain.Decimal
+	planned  domain.Decimal
+	accepted bool
+}
+
+// routeLevel is one price level of one venue, ranked by its price after
+// fees.
+type routeLevel struct {
+	v         *venue
+	price     domain.Decimal
+	quantity  domain.Decimal
+	effective domain.Decimal
+}
+
+// routeOrder splits a marketable order across every exchange with a usable
+// account, taking the levels with the best price after taker fees first
+// and never planning more on an exchange than its free balance covers.
+// Each exchange's part is placed as a child order of the same type; the
+// returned parent sums their fills. Quantity no exchange can take, or
+// whose part falls below an exchange's minimums, is not placed.
+func (s *TradingService) routeOrder(ctx context.Context, req domain.OrderRequest) (domain.OrderResponse, error) {
+	if err := s.checkHalt(); err != nil {
+		return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", err)
+	}
+	if err := checkRoutable(req); err != nil {
+		return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", err)
+	}
+	if err := s.checkRouteRisk(ctx, req); err != nil {
+		return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", err)
+	}
+
+	venues := s.routeVenues(ctx, req)
+	if len(venues) == 0 {
+		return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", &domain.OrderValidationError{Rule: domain.RuleRouting, Field: "Quantity", Symbol: req.Symbol})
+	}
+	planRoute(req, venues)
+
+	children := make([]domain.OrderRequest, len(venues))
+	for i, v := range venues {
+		if !v.planned.IsPositive() {
+			continue
+		}
+		child := domain.OrderRequest{
+			Symbol:      req.Symbol,
+			Side:        req.Side,
+			Type:        req.Type,
+			Quantity:    v.planned,
+			Price:       req.Price,
+			TimeInForce: req.TimeInForce,
+			Timestamp:   req.Timestamp,
+		}
+		child, err := v.x.Markets.Normalize(ctx, child)
+		if err != nil {
+			s.log.Info(ctx, "routeOrder: part dropped", ports.Fields{"exchange": v.x.Name, "quantity": v.planned, "error": err})
+			continue
+		}
+		children[i], v.accepted = child, true
+	}
+
+	placed := make([]*domain.OrderResponse, len(venues))
+	errs := make([]error, len(venues))
+	forEachLimit(len(venues), len(venues), func(i int) {
+		v := venues[i]
+		if !v.accepted {
+			return
+		}
+		resp, err := s.placeOrder(ctx, v.x, v.a, children[i])
+		if err != nil {
+			s.log.Error(ctx, "routeOrder: part failed", ports.Fields{"exchange": v.x.Name, "account": v.a.Name, "quantity": children[i].Quantity, "error": err})
+			errs[i] = fmt.Errorf("%s: %w", v.x.Name, err)
+			return
+		}
+		resp = v.a.tagOrder(v.a.orders.observe(ctx, resp))
+		resp.Exchange = v.x.Name
+		placed[i] = &resp
+	})
+
+	parent, err := routedParent(req, placed)
+	if err != nil {
+		return domain.OrderResponse{}, err
+	}
+	if len(parent.Children) == 0 {
+		if err := errors.Join(errs...); err != nil {
+			return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", err)
+		}
+		return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", &domain.OrderValidationError{Rule: domain.RuleRouting, Field: "Quantity", Symbol: req.Symbol})
+	}
+	s.log.Info(ctx, "routeOrder done", ports.Fields{"orderID": parent.ID, "symbol": req.Symbol, "parts": len(parent.Children), "filled": parent.FilledQuantity})
+	return parent, nil
+}
+
+// checkRoutable rejects orders that cannot be split: only market and
+// limit orders are routed, and neither post-only nor fill-or-kill can be
+// honoured across several exchanges.
+func checkRoutable(req domain.OrderRequest) error {
+	switch {
+	case req.Type != domain.TypeMarket && req.Type != domain.TypeLimit:
+		return &domain.OrderValidationError{Rule: domain.RuleRouting, Field: "Type", Symbol: req.Symbol}
+	case req.PostOnly:
+		return &domain.OrderValidationError{Rule: domain.RuleRouting, Field: "PostOnly", Symbol: req.Symbol}
+	case req.TimeInForce == domain.TimeInForceFOK:
+		return &domain.OrderValidationError{Rule: domain.RuleRouting, Field: "TimeInForce", Symbol: req.Symbol}
+	case req.Type == domain.TypeLimit && req.Price == nil:
+		return &domain.OrderValidationError{Rule: domain.RulePriceRequired, Field: "Price", Symbol: req.Symbol}
+	case !req.Quantity.IsPositive():
+		return &domain.OrderValidationError{Rule: domain.RuleMinQuantity, Field: "Quantity", Symbol: req.Symbol, Value: req.Quantity}
+	}
+	if err := checkOrderFields(req); err != nil {
+		return err
+	}
+	return checkOrderOptions(req)
+}
+
+// checkRouteRisk runs the risk rules on the whole order, before it is
+// split, against every exchange with a usable account, so that limits
+// apply to the order as sent rather than to each exchange's part. The
+// parts are checked again when placed. Exchanges the rules cannot be run
+// on are left to routeVenues, unless no exchange can be checked at all.
+func (s *TradingService) checkRouteRisk(ctx context.Context, req domain.OrderRequest) error {
+	exchanges := s.exchanges.All()
+	errs := make([]error, len(exchanges))
+	usable := make([]bool, len(exchanges))
+	forEachLimit(len(exchanges), len(exchanges), func(i int) {
+		x := exchanges[i]
+		a, err := x.account(ctx)
+		if err != nil {
+			return
+		}
+		usable[i] = true
+		if err := s.cfg.Risk.check(ctx, x, a, req); err != nil {
+			errs[i] = fmt.Errorf("%s: %w", x.Name, err)
+		}
+	})
+
+	checked := false
+	var failed []error
+	for i, err := range errs {
+		var rerr *domain.RiskError
+		if errors.As(err, &rerr) {
+			return err
+		}
+		if err != nil {
+			s.log.Error(ctx, "routeOrder: risk check failed", ports.Fields{"exchange": exchanges[i].Name, "error": err})
+			failed = append(failed, err)
+			continue
+		}
+		checked = checked || usable[i]
+	}
+	if !checked && len(failed) > 0 {
+		return errors.Join(failed...)
+	}
+	return nil
+}
+
+// routeVenues loads the book and balance of every exchange the order can
+// go to. Exchanges without a usable account for ctx, or whose book or
+// balance cannot be read, are left out.
+func (s *TradingService) routeVenues(ctx context.Context, req domain.OrderRequest) []*venue {
+	exchanges := s.exchanges.All()
+	found := make([]*venue, len(exchanges))
+	forEachLimit(len(exchanges), len(exchanges), func(i int) {
+		x := exchanges[i]
+		a, err := x.account(ctx)
+		if err != nil {
+			s.log.Debug(ctx, "routeOrder: exchange skipped", ports.Fields{"exchange": x.Name, "error": err})
+			return
+		}
+		var (
+			wg                  sync.WaitGroup
+			book                domain.OrderBook
+			balances            []domain.Balance
+			bookErr, balanceErr error
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			book, bookErr = x.Books.OrderBook(ctx, req.Symbol)
+		}()
+		go func() {
+			defer wg.Done()
+			balances, balanceErr = a.API.GetBalance(ctx)
+		}()
+		wg.Wait()
+		if err := errors.Join(bookErr, balanceErr); err != nil {
+			s.log.Error(ctx, "routeOrder: exchange skipped", ports.Fields{"exchange": x.Name, "account": a.Name, "error": err})
+			return
+		}
+
+		asset := req.Symbol.Base
+		if req.Side == domain.SideBuy {
+			asset = req.Symbol.Quote
+		}
+		v := &venue{x: x, a: a, book: book}
+		for _, b := range balances {
+			if b.Asset == asset {
+				v.budget = b.Free
+			}
+		}
+		found[i] = v
+	})
+
+	var out []*venue
+	for _, v := range found {
+		if v != nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// planRoute sets each venue's planned quantity. Levels from all books are
+// taken best price after fees first, within the limit price, until the
+// order is covered; a venue's levels stop counting once its budget is
+// spent. Levels without a positive price are ignored.
+func planRoute(req domain.OrderRequest, venues []*venue) {
+	one := domain.NewDecimalFromInt(1)
+	var levels []routeLevel
+	for _, v := range venues {
+		side, better := v.book.Asks, -1
+		factor := one.Add(v.x.TakerFee)
+		if req.Side == domain.SideSell {
+			side, better = v.book.Bids, 1
+			factor = one.Sub(v.x.TakerFee)
+		}
+		for _, l := range side {
+			if !l.Quantity.IsPositive() || !l.Price.IsPositive() {
+				continue
+			}
+			if req.Price != nil && l.Price.Cmp(*req.Price) != better && !l.Price.Equal(*req.Price) {
+				continue
+			}
+			effective := l.Price.Mul(factor)
+			if !effective.IsPositive() {
+				continue
+			}
+			levels = append(levels, routeLevel{v: v, price: l.Price, quantity: l.Quantity, effective: effective})
+		}
+	}
+	sort.SliceStable(levels, func(i, j int) bool {
+		if req.Side == domain.SideBuy {
+			return levels[i].effective.LessThan(levels[j].effective)
+		}
+		return levels[i].effective.GreaterThan(levels[j].effective)
+	})
+
+	remaining := req.Quantity
+	for _, l := range levels {
+		if !remaining.IsPositive() {
+			break
+		}
+		take := domain.MinDecimal(l.quantity, remaining)
+		if req.Side == domain.SideBuy {
+			affordable := l.v.budget.Div(l.effective, routeQuantityScale, domain.RoundDown)
+			take = domain.MinDecimal(take, affordable)
+		} else {
+			take = domain.MinDecimal(take, l.v.budget)
+		}
+		if !take.IsPositive() {
+			continue
+		}
+		if req.Side == domain.SideBuy {
+			l.v.budget = l.v.budget.Sub(take.Mul(l.effective))
+		} else {
+			l.v.budget = l.v.budget.Sub(take)
+		}
+		l.v.planned = l.v.planned.Add(take)
+		remaining = remaining.Sub(take)
+	}
+}
+
+// routedParent sums the placed children into the parent order. The parent
+// is filled once the whole request is, and otherwise open while any child
+// is.
+func routedParent(req domain.OrderRequest, placed []*domain.OrderResponse) (domain.OrderResponse, error) {
+	id, err := newOrderID(routedIDPrefix)
+	if err != nil {
+		return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", err)
+	}
+	parent := domain.OrderResponse{
+		ID:        id,
+		Symbol:    req.Symbol,
+		Side:      req.Side,
+		Type:      req.Type,
+		Quantity:  req.Quantity,
+		RawStatus: rawRouted,
+		Timestamp: time.Now(),
+	}
+	if req.Price != nil {
+		parent.Price = *req.Price
+	}
+
+	var notional domain.Decimal
+	open := false
+	for _, c := range placed {
+		if c == nil {
+			continue
+		}
+		parent.Children = append(parent.Children, *c)
+		parent.FilledQuantity = parent.FilledQuantity.Add(c.FilledQuantity)
+		notional = notional.Add(c.FilledQuantity.Mul(c.AvgPrice))
+		if !c.Status.IsFinal() {
+			open = true
+		}
+	}
+	parent.AvgPrice = domain.AveragePrice(notional, parent.FilledQuantity)
+
+	switch {
+	case parent.FilledQuantity.Cmp(parent.Quantity) >= 0:
+		parent.Status = domain.StatusFilled
+	case open && parent.FilledQuantity.IsPositive():
+		parent.Status = domain.StatusPartiallyFilled
+	case open:
+		parent.Status = domain.StatusNew
+	default:
+		parent.Status = domain.StatusCanceled
+	}
+	return parent, nil
+}
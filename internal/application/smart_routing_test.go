@@ -0,0 +1,105 @@
+package application
+
+import (
+	"testing"
+
+	"trade/internal/domain"
+)
+
+func TestPlanRoute(t *testing.T) {
+	type book struct {
+		fee    string
+		budget string
+		levels []domain.DepthLevel
+	}
+	tests := []struct {
+		name  string
+		side  domain.OrderSide
+		qty   string
+		price string
+		books []book
+		want  []string
+	}{
+		{
+			name:  "best price first across books",
+			side:  domain.SideBuy,
+			qty:   "3",
+			books: []book{{"0", "1000000", []domain.DepthLevel{lvl("100", "1"), lvl("102", "5")}}, {"0", "1000000", []domain.DepthLevel{lvl("101", "2")}}},
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "fees change the ranking",
+			side:  domain.SideBuy,
+			qty:   "1",
+			books: []book{{"0.02", "1000000", []domain.DepthLevel{lvl("100", "5")}}, {"0", "1000000", []domain.DepthLevel{lvl("101", "5")}}},
+			want:  []string{"0", "1"},
+		},
+		{
+			name:  "limit price excludes worse levels",
+			side:  domain.SideBuy,
+			qty:   "5",
+			price: "101",
+			books: []book{{"0", "1000000", []domain.DepthLevel{lvl("100", "1"), lvl("102", "5")}}, {"0", "1000000", []domain.DepthLevel{lvl("101", "2")}}},
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "quote budget caps a buy",
+			side:  domain.SideBuy,
+			qty:   "3",
+			books: []book{{"0", "1000000", []domain.DepthLevel{lvl("102", "5")}}, {"0", "202", []domain.DepthLevel{lvl("101", "5")}}},
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "sell takes the highest bids",
+			side:  domain.SideSell,
+			qty:   "4",
+			books: []book{{"0", "100", []domain.DepthLevel{lvl("99", "3"), lvl("97", "5")}}, {"0", "100", []domain.DepthLevel{lvl("98", "5")}}},
+			want:  []string{"3", "1"},
+		},
+		{
+			name:  "base budget caps a sell",
+			side:  domain.SideSell,
+			qty:   "4",
+			books: []book{{"0", "1", []domain.DepthLevel{lvl("99", "3")}}, {"0", "100", []domain.DepthLevel{lvl("98", "5")}}},
+			want:  []string{"1", "3"},
+		},
+		{
+			name:  "not enough depth",
+			side:  domain.SideBuy,
+			qty:   "10",
+			books: []book{{"0", "1000000", []domain.DepthLevel{lvl("100", "1")}}, {"0", "1000000", []domain.DepthLevel{lvl("101", "2")}}},
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "non-positive levels are ignored",
+			side:  domain.SideBuy,
+			qty:   "2",
+			books: []book{{"0", "1000000", []domain.DepthLevel{lvl("0", "5"), lvl("-1", "5"), lvl("100", "0")}}, {"0", "1000000", []domain.DepthLevel{lvl("101", "5")}}},
+			want:  []string{"0", "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := domain.OrderRequest{Symbol: btcIRT, Side: tt.side, Type: domain.TypeMarket, Quantity: dec(tt.qty)}
+			if tt.price != "" {
+				req.Type, req.Price = domain.TypeLimit, decp(tt.price)
+			}
+			venues := make([]*venue, len(tt.books))
+			for i, b := range tt.books {
+				ob := domain.OrderBook{Symbol: btcIRT}
+				if tt.side == domain.SideBuy {
+					ob.Asks = b.levels
+				} else {
+					ob.Bids = b.levels
+				}
+				venues[i] = &venue{x: &Exchange{TakerFee: dec(b.fee)}, book: ob, budget: dec(b.budget)}
+			}
+			planRoute(req, venues)
+			for i, v := range venues {
+				if !v.planned.Equal(dec(tt.want[i])) {
+					t.Errorf("venue %d planned %s, want %s", i, v.planned, tt.want[i])
+				}
+			}
+		})
+	}
+}
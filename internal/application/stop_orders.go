@@ -0,0 +1,519 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/orderbook"
+	"trade/internal/ports"
+)
+
+// emulatedIDPrefix marks order IDs issued by StopOrderManager rather than
+// by the exchange.
+const emulatedIDPrefix = "emu-"
+
+// stopRetention is how long finished emulated orders stay queryable.
+const stopRetention = 24 * time.Hour
+
+// legRefreshAttempts and legRefreshBackoff bound the reads of an OCO's
+// limit leg after it was cancelled for the stop to fire.
+const (
+	legRefreshAttempts = 3
+	legRefreshBackoff  = 200 * time.Millisecond
+)
+
+// Raw statuses reported for emulated orders.
+const (
+	rawPendingTrigger = "pending_trigger"
+	rawTriggered      = "triggered"
+	rawCanceled       = "canceled"
+	rawTriggerFailed  = "trigger_failed"
+	rawLegExecuted    = "limit_leg_executed"
+)
+
+type stopState int
+
+const (
+	stopWaiting stopState = iota
+	stopFiring
+	stopTriggered
+	stopLegExecuted
+	stopCanceled
+	stopFailed
+)
+
+type stopOrder struct {
+	id       string
+	req      domain.OrderRequest
+	created  time.Time
+	finished time.Time
+	state    stopState
+
+	// limitLeg is the resting limit order of an OCO.
+	limitLeg *domain.OrderResponse
+	// child is the order placed when the trigger fired.
+	child *domain.OrderResponse
+	// fired is closed once a firing trigger has placed its child order.
+	fired chan struct{}
+}
+
+// placeFunc places the child order of a triggered stop; replaces is the
+// ID of the emulated order it stands in for.
+type placeFunc func(ctx context.Context, replaces string, req domain.OrderRequest) (domain.OrderResponse, error)
+
+// StopOrderManager emulates stop and OCO orders on exchanges that lack
+// them. It checks the shared order book of every symbol with a pending
+// stop and places the child order once the trigger price is crossed.
+type StopOrderManager struct {
+	exchange domain.ExchangePort
+	books    *orderbook.Manager
+	interval time.Duration
+	log      ports.LoggerPort
+
+	mu     sync.Mutex
+	orders map[string]*stopOrder
+	place  placeFunc
+	// held stops triggers from firing while trading is halted.
+	held atomic.Bool
+}
+
+// NewStopOrderManager starts the trigger watcher; it runs until ctx is done.
+func NewStopOrderManager(ctx context.Context, exch domain.ExchangePort, books *orderbook.Manager, interval time.Duration, log ports.LoggerPort) *StopOrderManager {
+	m := &StopOrderManager{
+		exchange: exch,
+		books:    books,
+		interval: interval,
+		log:      log,
+		orders:   make(map[string]*stopOrder),
+	}
+	m.place = func(ctx context.Context, _ string, req domain.OrderRequest) (domain.OrderResponse, error) {
+		return m.exchange.CreateOrder(ctx, req)
+	}
+	go m.run(ctx)
+	return m
+}
+
+// Hold keeps triggered orders from being placed while on is set; they
+// fire on the first poll after it is cleared if still triggered.
+func (m *StopOrderManager) Hold(on bool) {
+	m.held.Store(on)
+}
+
+// setPlacer routes child orders through place, which applies the checks
+// of a regular order, instead of straight to the exchange.
+func (m *StopOrderManager) setPlacer(place placeFunc) {
+	m.mu.Lock()
+	m.place = place
+	m.mu.Unlock()
+}
+
+// Owns reports whether orderID was issued by the manager.
+func (m *StopOrderManager) Owns(orderID string) bool {
+	return strings.HasPrefix(orderID, emulatedIDPrefix)
+}
+
+// Submit registers a conditional order. For OCO orders the limit leg is
+// placed on the exchange immediately.
+func (m *StopOrderManager) Submit(ctx context.Context, req domain.OrderRequest) (domain.OrderResponse, error) {
+	id, err := newEmulatedID()
+	if err != nil {
+		return domain.OrderResponse{}, err
+	}
+	so := &stopOrder{id: id, req: req, created: time.Now()}
+
+	if req.Type == domain.TypeOCO {
+		legReq := req
+		legReq.Type = domain.TypeLimit
+		legReq.TriggerPrice = nil
+		legReq.StopLimitPrice = nil
+		leg, err := m.exchange.CreateOrder(ctx, legReq)
+		if err != nil {
+			return domain.OrderResponse{}, fmt.Errorf("place OCO limit leg: %w", err)
+		}
+		so.limitLeg = &leg
+	}
+
+	m.mu.Lock()
+	m.orders[id] = so
+	view := so.view()
+	m.mu.Unlock()
+
+	m.log.Info(ctx, "emulated stop order accepted", ports.Fields{
+		"orderID": id,
+		"symbol":  req.Symbol,
+		"type":    req.Type,
+		"trigger": req.TriggerPrice,
+	})
+	return view, nil
+}
+
+// Get returns the emulated order, refreshing its child orders first.
+func (m *StopOrderManager) Get(ctx context.Context, orderID string) (domain.OrderResponse, error) {
+	m.mu.Lock()
+	so, ok := m.orders[orderID]
+	if !ok {
+		m.mu.Unlock()
+		return domain.OrderResponse{}, fmt.Errorf("unknown order %s", orderID)
+	}
+	legs := []**domain.OrderResponse{&so.limitLeg, &so.child}
+	m.mu.Unlock()
+
+	for _, leg := range legs {
+		m.mu.Lock()
+		current := *leg
+		m.mu.Unlock()
+		if current == nil || current.Status.IsFinal() {
+			continue
+		}
+		if fresh, err := m.exchange.GetOrder(ctx, current.Symbol, current.ID); err == nil {
+			m.mu.Lock()
+			*leg = &fresh
+			m.mu.Unlock()
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return so.view(), nil
+}
+
+// Open lists emulated orders still waiting for their trigger.
+func (m *StopOrderManager) Open(symbol *domain.Symbol) []domain.OrderResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []domain.OrderResponse
+	for _, so := range m.orders {
+		if so.state != stopWaiting && so.state != stopFiring {
+			continue
+		}
+		if symbol != nil && so.req.Symbol != *symbol {
+			continue
+		}
+		out = append(out, so.view())
+	}
+	return out
+}
+
+// Cancel stops watching a pending order and cancels any order already
+// placed for it on the exchange. It fails while the order's trigger is
+// firing.
+func (m *StopOrderManager) Cancel(ctx context.Context, orderID string) error {
+	return m.cancel(ctx, orderID, false)
+}
+
+// CancelWait is Cancel, except that an order whose trigger is firing is
+// waited for and the child order it placed is cancelled.
+func (m *StopOrderManager) CancelWait(ctx context.Context, orderID string) error {
+	return m.cancel(ctx, orderID, true)
+}
+
+func (m *StopOrderManager) cancel(ctx context.Context, orderID string, wait bool) error {
+	for {
+		m.mu.Lock()
+		so, ok := m.orders[orderID]
+		if !ok {
+			m.mu.Unlock()
+			return fmt.Errorf("unknown order %s", orderID)
+		}
+		var onExchange **domain.OrderResponse
+		switch so.state {
+		case stopWaiting:
+			so.state = stopCanceled
+			so.finished = time.Now()
+			onExchange = &so.limitLeg
+		case stopFiring:
+			fired := so.fired
+			m.mu.Unlock()
+			if !wait {
+				return errors.New("order is being triggered, retry shortly")
+			}
+			select {
+			case <-fired:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case stopTriggered:
+			onExchange = &so.child
+		case stopLegExecuted:
+			onExchange = &so.limitLeg
+		}
+		var leg *domain.OrderResponse
+		if onExchange != nil {
+			leg = *onExchange
+		}
+		m.mu.Unlock()
+
+		if leg == nil || leg.Status.IsFinal() {
+			return nil
+		}
+		err := m.exchange.CancelOrder(ctx, leg.Symbol, leg.ID)
+		if err == nil {
+			return nil
+		}
+		// The order may already be gone from the book, e.g. filled or
+		// removed by a bulk cancel, which is not a failure.
+		fresh, gerr := m.exchange.GetOrder(ctx, leg.Symbol, leg.ID)
+		if gerr != nil || !fresh.Status.IsFinal() {
+			return err
+		}
+		m.mu.Lock()
+		*onExchange = &fresh
+		m.mu.Unlock()
+		return nil
+	}
+}
+
+func (m *StopOrderManager) run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.poll(ctx)
+		}
+	}
+}
+
+// poll evaluates every waiting order against a fresh book, reading each
+// symbol's book once.
+func (m *StopOrderManager) poll(ctx context.Context) {
+	bySymbol := make(map[domain.Symbol][]*stopOrder)
+	m.mu.Lock()
+	for id, so := range m.orders {
+		if so.state == stopWaiting {
+			bySymbol[so.req.Symbol] = append(bySymbol[so.req.Symbol], so)
+		} else if !so.finished.IsZero() && time.Since(so.finished) > stopRetention {
+			delete(m.orders, id)
+		}
+	}
+	m.mu.Unlock()
+
+	if m.held.Load() {
+		return
+	}
+	for symbol, pending := range bySymbol {
+		book, err := m.books.OrderBook(ctx, symbol)
+		if err != nil {
+			m.log.Error(ctx, "stop watcher: order book unavailable", ports.Fields{"symbol": symbol, "error": err})
+			continue
+		}
+		for _, so := range pending {
+			if m.limitLegExecuted(ctx, so) {
+				continue
+			}
+			if so.req.Triggered(book) {
+				m.fire(ctx, so)
+			}
+		}
+	}
+}
+
+// limitLegExecuted refreshes an OCO's limit leg and retires the stop once
+// the leg is final: filled, cancelled, rejected or expired. A partially
+// filled leg keeps the stop armed; if it fires, the child covers only the
+// quantity the leg left unfilled. It is a no-op for plain stops.
+func (m *StopOrderManager) limitLegExecuted(ctx context.Context, so *stopOrder) bool {
+	m.mu.Lock()
+	current := so.limitLeg
+	m.mu.Unlock()
+	if current == nil {
+		return false
+	}
+
+	leg, err := m.exchange.GetOrder(ctx, current.Symbol, current.ID)
+	if err != nil {
+		m.log.Error(ctx, "stop watcher: OCO leg refresh failed", ports.Fields{"orderID": so.id, "error": err})
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	so.limitLeg = &leg
+	if so.state != stopWaiting || !leg.Status.IsFinal() {
+		return so.state != stopWaiting
+	}
+	so.state = stopLegExecuted
+	so.finished = time.Now()
+	m.log.Info(ctx, "OCO limit leg executed, stop leg dropped", ports.Fields{"orderID": so.id, "legStatus": leg.Status})
+	return true
+}
+
+// fire places the child order of a triggered stop. An OCO's limit leg is
+// cancelled first and the child covers what the leg left unfilled; when
+// the leg cannot be read back, nothing is placed and the stop fails. A
+// stop stays armed while its leg is still working or, if nothing was
+// touched yet, while trading is halted.
+func (m *StopOrderManager) fire(ctx context.Context, so *stopOrder) {
+	m.mu.Lock()
+	if so.state != stopWaiting || m.held.Load() {
+		m.mu.Unlock()
+		return
+	}
+	so.state = stopFiring
+	so.fired = make(chan struct{})
+	leg := so.limitLeg
+	place := m.place
+	m.mu.Unlock()
+
+	qty := so.req.Quantity
+	if leg != nil {
+		cerr := m.exchange.CancelOrder(ctx, leg.Symbol, leg.ID)
+		fresh, err := m.refreshLeg(ctx, *leg)
+		if err != nil {
+			m.settle(so, stopFailed, nil, nil)
+			m.log.Error(ctx, "stop trigger: OCO limit leg unreadable after cancel", ports.Fields{"orderID": so.id, "error": err})
+			return
+		}
+		if !fresh.Status.IsFinal() {
+			m.settle(so, stopWaiting, &fresh, nil)
+			m.log.Error(ctx, "OCO limit leg cancel failed", ports.Fields{"orderID": so.id, "error": cerr})
+			return
+		}
+		leg = &fresh
+		qty = qty.Sub(fresh.FilledQuantity)
+	}
+	if !qty.IsPositive() {
+		m.settle(so, stopLegExecuted, leg, nil)
+		return
+	}
+
+	child, err := place(ctx, so.id, so.childRequest(qty))
+	switch {
+	case err != nil && leg == nil && errors.Is(err, ErrTradingHalted):
+		m.settle(so, stopWaiting, nil, nil)
+	case err != nil:
+		m.settle(so, stopFailed, leg, nil)
+		m.log.Error(ctx, "stop trigger: child order failed", ports.Fields{"orderID": so.id, "error": err})
+	default:
+		m.settle(so, stopTriggered, leg, &child)
+		m.log.Info(ctx, "stop order triggered", ports.Fields{
+			"orderID": so.id,
+			"childID": child.ID,
+			"symbol":  so.req.Symbol,
+			"trigger": so.req.TriggerPrice,
+		})
+	}
+}
+
+// refreshLeg reads an OCO's limit leg, retrying a few times.
+func (m *StopOrderManager) refreshLeg(ctx context.Context, leg domain.OrderResponse) (domain.OrderResponse, error) {
+	var err error
+	for attempt := 0; attempt < legRefreshAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(legRefreshBackoff):
+			case <-ctx.Done():
+				return domain.OrderResponse{}, ctx.Err()
+			}
+		}
+		var fresh domain.OrderResponse
+		if fresh, err = m.exchange.GetOrder(ctx, leg.Symbol, leg.ID); err == nil {
+			return fresh, nil
+		}
+	}
+	return domain.OrderResponse{}, err
+}
+
+// settle ends a firing in state, recording the limit leg, when read, and
+// the child order, and releases anyone waiting on it.
+func (m *StopOrderManager) settle(so *stopOrder, state stopState, leg, child *domain.OrderResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	so.state = state
+	if leg != nil {
+		so.limitLeg = leg
+	}
+	so.child = child
+	if state != stopWaiting {
+		so.finished = time.Now()
+	}
+	close(so.fired)
+}
+
+// childRequest builds the order placed when the trigger fires.
+func (so *stopOrder) childRequest(qty domain.Decimal) domain.OrderRequest {
+	child := so.req
+	child.Quantity = qty
+	child.TriggerPrice = nil
+	child.StopLimitPrice = nil
+	switch so.req.Type {
+	case domain.TypeStopLimit:
+		child.Type = domain.TypeLimit
+	case domain.TypeOCO:
+		child.Type = domain.TypeMarket
+		child.Price = nil
+		if so.req.StopLimitPrice != nil {
+			child.Type = domain.TypeLimit
+			child.Price = so.req.StopLimitPrice
+		}
+	default:
+		child.Type = domain.TypeMarket
+		child.Price = nil
+	}
+	return child
+}
+
+// view renders the emulated order; callers must hold the manager lock.
+func (so *stopOrder) view() domain.OrderResponse {
+	out := domain.OrderResponse{
+		ID:        so.id,
+		Symbol:    so.req.Symbol,
+		Side:      so.req.Side,
+		Type:      so.req.Type,
+		Quantity:  so.req.Quantity,
+		Timestamp: so.created,
+	}
+	if so.req.Price != nil {
+		out.Price = *so.req.Price
+	}
+
+	var filledQuote domain.Decimal
+	for _, c := range []*domain.OrderResponse{so.limitLeg, so.child} {
+		if c == nil {
+			continue
+		}
+		out.Children = append(out.Children, *c)
+		out.FilledQuantity = out.FilledQuantity.Add(c.FilledQuantity)
+		filledQuote = filledQuote.Add(c.FilledQuantity.Mul(c.AvgPrice))
+	}
+	out.AvgPrice = domain.AveragePrice(filledQuote, out.FilledQuantity)
+
+	switch so.state {
+	case stopWaiting, stopFiring:
+		out.Status, out.RawStatus = domain.StatusNew, rawPendingTrigger
+	case stopTriggered:
+		out.Status, out.RawStatus = so.child.Status, rawTriggered
+	case stopLegExecuted:
+		out.Status, out.RawStatus = so.limitLeg.Status, rawLegExecuted
+	case stopCanceled:
+		out.Status, out.RawStatus = domain.StatusCanceled, rawCanceled
+	case stopFailed:
+		out.Status, out.RawStatus = domain.StatusRejected, rawTriggerFailed
+	}
+	return out
+}
+
+func newEmulatedID() (string, error) {
+	return newOrderID(emulatedIDPrefix)
+}
+
+// newOrderID issues a random ID for an order that exists only in this
+// process.
+func newOrderID(prefix string) (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"trade/internal/adapters/fake"
+	"trade/internal/domain"
+)
+
+func TestStopOrderStates(t *testing.T) {
+	stop := func(typ domain.OrderType, side domain.OrderSide, trigger string) domain.OrderRequest {
+		req := domain.OrderRequest{Symbol: btcIRT, Side: side, Type: typ, Quantity: dec("2"), TriggerPrice: decp(trigger)}
+		if typ == domain.TypeStopLimit {
+			req.Price = decp("95")
+		}
+		return req
+	}
+	oco := func(trigger string) domain.OrderRequest {
+		req := stop(domain.TypeOCO, domain.SideSell, trigger)
+		req.Price = decp("120")
+		return req
+	}
+	// leg moves the OCO limit leg, which the fake placed first as "1".
+	leg := func(status domain.OrderStatus, filled string) func(*fake.Exchange, *StopOrderManager) {
+		return func(f *fake.Exchange, _ *StopOrderManager) {
+			o, _ := f.Order("1")
+			o.Status, o.FilledQuantity = status, dec(filled)
+			f.SetOrder(o)
+		}
+	}
+	placer := func(err error) func(*fake.Exchange, *StopOrderManager) {
+		return func(_ *fake.Exchange, m *StopOrderManager) {
+			m.setPlacer(func(context.Context, string, domain.OrderRequest) (domain.OrderResponse, error) {
+				return domain.OrderResponse{}, err
+			})
+		}
+	}
+
+	// The book is bid 99, ask 101: sell stops at or above 99 and buy stops
+	// at or below 101 trigger.
+	tests := []struct {
+		name  string
+		req   domain.OrderRequest
+		setup func(*fake.Exchange, *StopOrderManager)
+		want  stopState
+		child string
+	}{
+		{"stop not triggered", stop(domain.TypeStopMarket, domain.SideSell, "95"), nil, stopWaiting, ""},
+		{"stop market triggered", stop(domain.TypeStopMarket, domain.SideSell, "100"), nil, stopTriggered, "MARKET 2"},
+		{"stop limit triggered", stop(domain.TypeStopLimit, domain.SideBuy, "100"), nil, stopTriggered, "LIMIT 2 @95"},
+		{"OCO not triggered", oco("95"), nil, stopWaiting, ""},
+		{"OCO triggered", oco("100"), nil, stopTriggered, "MARKET 2"},
+		{"OCO leg partly filled", oco("95"), leg(domain.StatusPartiallyFilled, "0.5"), stopWaiting, ""},
+		{"OCO leg partly filled, triggered", oco("100"), leg(domain.StatusPartiallyFilled, "0.5"), stopTriggered, "MARKET 1.5"},
+		{"OCO leg filled", oco("100"), leg(domain.StatusFilled, "2"), stopLegExecuted, ""},
+		{"OCO leg canceled", oco("95"), leg(domain.StatusCanceled, "0"), stopLegExecuted, ""},
+		{"OCO leg unreadable", oco("100"), func(f *fake.Exchange, _ *StopOrderManager) { f.Fail("GetOrder", errors.New("down")) }, stopFailed, ""},
+		{"held", stop(domain.TypeStopMarket, domain.SideSell, "100"), func(_ *fake.Exchange, m *StopOrderManager) { m.Hold(true) }, stopWaiting, ""},
+		{"halted at placement", stop(domain.TypeStopMarket, domain.SideSell, "100"), placer(ErrTradingHalted), stopWaiting, ""},
+		{"rejected at placement", stop(domain.TypeStopMarket, domain.SideSell, "100"), placer(&domain.RiskError{Rule: domain.RiskMaxNotional}), stopFailed, ""},
+		{"OCO halted after leg cancel", oco("100"), placer(ErrTradingHalted), stopFailed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f, _, a := newTestVenue(t, "alpha")
+			m := a.Stops
+			placed, err := m.Submit(ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			legs := len(f.Placed())
+			if tt.setup != nil {
+				tt.setup(f, m)
+			}
+			m.poll(ctx)
+
+			m.mu.Lock()
+			so := m.orders[placed.ID]
+			state := so.state
+			m.mu.Unlock()
+			if state != tt.want {
+				t.Errorf("state = %d, want %d", state, tt.want)
+			}
+
+			children := f.Placed()[legs:]
+			var child string
+			if len(children) > 0 {
+				c := children[len(children)-1]
+				child = fmt.Sprintf("%s %s", c.Type, c.Quantity)
+				if c.Price != nil {
+					child += " @" + c.Price.String()
+				}
+			}
+			if len(children) > 1 || child != tt.child {
+				t.Errorf("placed %d children, last %q, want %q", len(children), child, tt.child)
+			}
+		})
+	}
+}
+
+func TestStopOrderCancel(t *testing.T) {
+	ctx := context.Background()
+	f, _, a := newTestVenue(t, "alpha")
+	m := a.Stops
+
+	waiting, err := m.Submit(ctx, domain.OrderRequest{Symbol: btcIRT, Side: domain.SideSell, Type: domain.TypeOCO, Quantity: dec("1"), Price: decp("120"), TriggerPrice: decp("90")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Cancel(ctx, waiting.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.Get(ctx, waiting.ID); got.Status != domain.StatusCanceled {
+		t.Errorf("status = %s, want CANCELED", got.Status)
+	}
+	if leg, _ := f.Order("1"); leg.Status != domain.StatusCanceled {
+		t.Errorf("limit leg %s, want CANCELED", leg.Status)
+	}
+
+	// A triggered stop cancels its child.
+	fired, err := m.Submit(ctx, domain.OrderRequest{Symbol: btcIRT, Side: domain.SideSell, Type: domain.TypeStopLimit, Quantity: dec("1"), Price: decp("95"), TriggerPrice: decp("100")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.poll(ctx)
+	if err := m.CancelWait(ctx, fired.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := m.Get(ctx, fired.ID)
+	if got.RawStatus != rawTriggered || got.Status != domain.StatusCanceled {
+		t.Errorf("status = %s (%s), want CANCELED (%s)", got.Status, got.RawStatus, rawTriggered)
+	}
+	if open := m.Open(nil); len(open) != 0 {
+		t.Errorf("%d orders still open", len(open))
+	}
+}
@@ -0,0 +1,230 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// Stream channel kinds. Market channels are named "<kind>:<SYMBOL>", e.g.
+// "book:BTC-IRT"; the private channel is just "user".
+const (
+	ChannelBook   = "book"
+	ChannelTicker = "ticker"
+	ChannelTrades = "trades"
+	ChannelUser   = "user"
+)
+
+// hubClientBuffer is the number of undelivered messages a client may have
+// queued before it is dropped from a channel.
+const hubClientBuffer = 512
+
+// StreamMessage is one value published on a hub channel: a
+// domain.BookUpdate, domain.Ticker, domain.PublicTrade or domain.UserEvent.
+type StreamMessage struct {
+	Channel string
+	Data    interface{}
+}
+
+// feed is one upstream subscription and the clients sharing it.
+type feed struct {
+	cancel  context.CancelFunc
+	clients map[chan StreamMessage]struct{}
+
+	// book is the feed's copy of the order book on book channels, used to
+	// give late joiners a snapshot.
+	book     domain.OrderBook
+	bookSeq  uint64
+	haveBook bool
+}
+
+// StreamHub shares stream subscriptions between any number of clients.
+// The first client of a channel subscribes upstream, later ones join that
+// subscription, and the last one to leave releases it.
+type StreamHub struct {
+	exchanges *ExchangeRegistry
+	log       ports.LoggerPort
+
+	mu sync.Mutex
+	// feeds is keyed by exchange name and channel, see feedKey.
+	feeds map[string]*feed
+}
+
+func NewStreamHub(exchanges *ExchangeRegistry, log ports.LoggerPort) *StreamHub {
+	return &StreamHub{
+		exchanges: exchanges,
+		log:       log,
+		feeds:     make(map[string]*feed),
+	}
+}
+
+// Subscribe joins channel on the exchange selected by ctx until ctx is
+// done; the user channel follows the account selected by ctx. The
+// returned channel is also closed if the client falls too far behind or
+// the upstream subscription ends; the client may subscribe again. Book
+// channels start with a snapshot.
+func (h *StreamHub) Subscribe(ctx context.Context, channel string) (<-chan StreamMessage, error) {
+	kind, arg, _ := strings.Cut(channel, ":")
+	var x *Exchange
+	var a *Account
+	var err error
+	if kind == ChannelUser {
+		x, a, err = h.exchanges.ResolveAccount(ctx)
+	} else {
+		x, err = h.exchanges.Resolve(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key := feedKey(x, a, channel)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, ok := h.feeds[key]
+	if !ok {
+		if f, err = h.open(x, a, key, channel, kind, arg); err != nil {
+			return nil, err
+		}
+		h.feeds[key] = f
+	}
+
+	ch := make(chan StreamMessage, hubClientBuffer)
+	f.clients[ch] = struct{}{}
+	if f.haveBook {
+		book := f.book.Copy()
+		ch <- StreamMessage{Channel: channel, Data: domain.BookUpdate{
+			Symbol: book.Symbol, Sequence: f.bookSeq, Snapshot: true, Bids: book.Bids, Asks: book.Asks, Time: time.Now(),
+		}}
+	}
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.leave(key, f, ch)
+	}()
+	return ch, nil
+}
+
+// feedKey names the upstream subscription of channel on x, or on account
+// a of x for the user channel.
+func feedKey(x *Exchange, a *Account, channel string) string {
+	if a != nil {
+		return x.Name + "/" + a.Name + "|" + channel
+	}
+	return x.Name + "|" + channel
+}
+
+// open subscribes upstream on x, or on a for the user channel, for a new
+// feed. Callers hold h.mu.
+func (h *StreamHub) open(x *Exchange, a *Account, key, channel, kind, arg string) (*feed, error) {
+	var symbol domain.Symbol
+	if kind != ChannelUser {
+		var err error
+		if symbol, err = domain.ParseSymbol(arg); err != nil {
+			return nil, fmt.Errorf("channel %q: %w", channel, err)
+		}
+	}
+
+	// Upstream subscriptions outlive the client that opened them, so they
+	// get their own context.
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &feed{cancel: cancel, clients: make(map[chan StreamMessage]struct{})}
+
+	var err error
+	switch kind {
+	case ChannelBook:
+		var up <-chan domain.BookUpdate
+		if up, err = x.Market.SubscribeBook(ctx, symbol); err == nil {
+			go pump(h, key, channel, f, up, func(u domain.BookUpdate) domain.BookUpdate {
+				f.applyBook(u)
+				return u
+			})
+		}
+	case ChannelTicker:
+		var up <-chan domain.Ticker
+		if up, err = x.Market.SubscribeTicker(ctx, symbol); err == nil {
+			go pump(h, key, channel, f, up, nil)
+		}
+	case ChannelTrades:
+		var up <-chan domain.PublicTrade
+		if up, err = x.Market.SubscribeTrades(ctx, symbol); err == nil {
+			go pump(h, key, channel, f, up, nil)
+		}
+	case ChannelUser:
+		var up <-chan domain.UserEvent
+		if up, err = a.User.SubscribeUser(ctx); err == nil {
+			go pump(h, key, channel, f, up, func(ev domain.UserEvent) domain.UserEvent {
+				ev.Account = a.Name
+				return ev
+			})
+		}
+	default:
+		err = fmt.Errorf("unknown channel %q", channel)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	h.log.Info(ctx, "stream feed opened", ports.Fields{"feed": key})
+	return f, nil
+}
+
+// pump forwards upstream values to the feed's clients until upstream
+// closes, then drops the feed. prepare, when set, sees every value under
+// the hub lock and returns what is forwarded.
+func pump[T any](h *StreamHub, key, channel string, f *feed, up <-chan T, prepare func(T) T) {
+	for v := range up {
+		h.mu.Lock()
+		if prepare != nil {
+			v = prepare(v)
+		}
+		msg := StreamMessage{Channel: channel, Data: v}
+		for ch := range f.clients {
+			select {
+			case ch <- msg:
+			default:
+				h.log.Error(context.Background(), "stream client lagging, dropping", ports.Fields{"channel": channel})
+				h.leave(key, f, ch)
+			}
+		}
+		h.mu.Unlock()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range f.clients {
+		h.leave(key, f, ch)
+	}
+	if h.feeds[key] == f {
+		delete(h.feeds, key)
+		f.cancel()
+	}
+}
+
+// leave removes one client and releases the upstream subscription with the
+// last one. Callers hold h.mu.
+func (h *StreamHub) leave(key string, f *feed, ch chan StreamMessage) {
+	if _, ok := f.clients[ch]; !ok {
+		return
+	}
+	delete(f.clients, ch)
+	close(ch)
+	if len(f.clients) == 0 && h.feeds[key] == f {
+		delete(h.feeds, key)
+		f.cancel()
+		h.log.Info(context.Background(), "stream feed closed", ports.Fields{"feed": key})
+	}
+}
+
+func (f *feed) applyBook(u domain.BookUpdate) {
+	f.book.Apply(u)
+	f.bookSeq = u.Sequence
+	f.haveBook = true
+}
@@ -0,0 +1,66 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// checkPostOnly emulates post-only by rejecting a limit order that would
+// cross the current book. The book can move before the order lands, so
+// this is best effort.
+func (s *TradingService) checkPostOnly(ctx context.Context, x *Exchange, req domain.OrderRequest) error {
+	book, err := x.Books.OrderBook(ctx, req.Symbol)
+	if err != nil {
+		return fmt.Errorf("post-only check: %w", err)
+	}
+
+	var opposite domain.DepthLevel
+	var ok, crosses bool
+	if req.Side == domain.SideBuy {
+		opposite, ok = book.BestAsk()
+		crosses = ok && req.Price.Cmp(opposite.Price) >= 0
+	} else {
+		opposite, ok = book.BestBid()
+		crosses = ok && req.Price.Cmp(opposite.Price) <= 0
+	}
+	if crosses {
+		return &domain.OrderValidationError{Rule: domain.RulePostOnly, Field: "Price", Symbol: req.Symbol, Value: *req.Price, Limit: opposite.Price}
+	}
+	return nil
+}
+
+// checkFillOrKill emulates the FOK pre-condition by requiring enough
+// opposite depth within the limit price to fill the whole order.
+func (s *TradingService) checkFillOrKill(ctx context.Context, x *Exchange, req domain.OrderRequest) error {
+	book, err := x.Books.OrderBook(ctx, req.Symbol)
+	if err != nil {
+		return fmt.Errorf("fill-or-kill check: %w", err)
+	}
+	available := book.FillableQuantity(req.Side, req.Price)
+	if available.LessThan(req.Quantity) {
+		return &domain.OrderValidationError{Rule: domain.RuleFillOrKill, Field: "Quantity", Symbol: req.Symbol, Value: req.Quantity, Limit: available}
+	}
+	return nil
+}
+
+// cancelRemainder emulates IOC/FOK for a limit order that was sent as GTC:
+// whatever did not fill on arrival is cancelled and the final state of
+// the order is returned.
+func (s *TradingService) cancelRemainder(ctx context.Context, a *Account, placed domain.OrderResponse) domain.OrderResponse {
+	if placed.Status.IsFinal() {
+		return placed
+	}
+	if err := a.API.CancelOrder(ctx, placed.Symbol, placed.ID); err != nil {
+		s.log.Error(ctx, "time-in-force: cancel of unfilled remainder failed", ports.Fields{"orderID": placed.ID, "error": err})
+	}
+	a.balances.invalidate()
+	final, err := a.API.GetOrder(ctx, placed.Symbol, placed.ID)
+	if err != nil {
+		s.log.Error(ctx, "time-in-force: refresh after cancel failed", ports.Fields{"orderID": placed.ID, "error": err})
+		return placed
+	}
+	return final
+}
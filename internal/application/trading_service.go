@@ -3,52 +3,351 @@ package application
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"trade/internal/domain"
 	"trade/internal/ports"
 )
 
+// ServiceConfig holds the tunables of TradingService.
+type ServiceConfig struct {
+	// CancelConcurrency bounds the parallel cancels of CancelAll when the
+	// exchange has no bulk cancel.
+	CancelConcurrency int
+	// BatchConcurrency bounds the parallel placements of CreateOrders.
+	BatchConcurrency int
+	// Risk checks every order before it is sent; nil checks nothing.
+	Risk *RiskEngine
+	// CheckFunds rejects orders the account's free balance cannot cover,
+	// going by balances cached for BalanceTTL.
+	CheckFunds bool
+	BalanceTTL time.Duration
+	// Halted starts the service with trading halted. HaltStore, when set,
+	// keeps the halt state across restarts.
+	Halted    bool
+	HaltStore domain.HaltStorePort
+}
+
+// TradingService runs every call against the exchange selected by the
+// call's context (see WithExchange), or the default exchange. Order,
+// balance and history calls act for the account selected with
+// WithAccount.
 type TradingService struct {
-	exchange domain.ExchangePort
-	log      ports.LoggerPort
+	exchanges *ExchangeRegistry
+	cfg       ServiceConfig
+	log       ports.LoggerPort
+
+	haltMu sync.RWMutex
+	halt   domain.HaltState
+}
+
+func NewTradingService(exchanges *ExchangeRegistry, cfg ServiceConfig, log ports.LoggerPort) *TradingService {
+	s := &TradingService{
+		exchanges: exchanges,
+		cfg:       cfg,
+		log:       log,
+	}
+	for _, x := range exchanges.All() {
+		for _, a := range x.Accounts {
+			a.balances = newBalanceCache(a.API, cfg.BalanceTTL)
+			if a.Stops != nil {
+				a.Stops.setPlacer(s.stopChildPlacer(x, a))
+			}
+		}
+	}
+	s.initHalt(context.Background())
+	return s
 }
 
-func NewTradingService(exch domain.ExchangePort, log ports.LoggerPort) *TradingService {
-	return &TradingService{exchange: exch, log: log}
+// Exchanges lists the configured exchanges, default first.
+func (s *TradingService) Exchanges() []string {
+	return s.exchanges.Names()
 }
 
+// Accounts lists the accounts of the named exchange.
+func (s *TradingService) Accounts(exchange string) ([]string, error) {
+	x, err := s.exchanges.Get(exchange)
+	if err != nil {
+		return nil, err
+	}
+	return x.AccountNames(), nil
+}
+
+// CreateOrder places req on the selected exchange, or splits it across
+// all exchanges when ctx was made with WithSmartRouting.
 func (s *TradingService) CreateOrder(ctx context.Context, req domain.OrderRequest) (domain.OrderResponse, error) {
-	resp, err := s.exchange.CreateOrder(ctx, req)
+	if smartRoutingFromContext(ctx) {
+		return s.routeOrder(ctx, req)
+	}
+	x, a, err := s.exchanges.ResolveAccount(ctx)
 	if err != nil {
-		s.log.Error(ctx, "CreateOrder failed", ports.Fields{"error": err})
+		return domain.OrderResponse{}, err
+	}
+	req, err = x.Markets.Normalize(ctx, req)
+	if err != nil {
+		s.log.Error(ctx, "CreateOrder rejected", ports.Fields{"account": a.Name, "symbol": req.Symbol, "error": err})
+		return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", err)
+	}
+
+	resp, err := s.placeOrder(ctx, x, a, req)
+	if err != nil {
+		s.log.Error(ctx, "CreateOrder failed", ports.Fields{"account": a.Name, "error": err})
 		return domain.OrderResponse{}, fmt.Errorf("CreateOrder failed: %w", err)
 	}
+	resp = a.orders.observe(ctx, resp)
+	return a.tagOrder(resp), nil
+}
+
+// placeOrder sends a validated order for a unless trading is halted or
+// the risk rules object.
+// Conditional types, time-in-force and post-only options the exchange
+// cannot take natively are emulated.
+func (s *TradingService) placeOrder(ctx context.Context, x *Exchange, a *Account, req domain.OrderRequest) (domain.OrderResponse, error) {
+	if err := s.checkHalt(); err != nil {
+		return domain.OrderResponse{}, err
+	}
+	if err := s.cfg.Risk.check(ctx, x, a, req); err != nil {
+		return domain.OrderResponse{}, err
+	}
+	if err := s.checkFunds(ctx, x, a, req); err != nil {
+		return domain.OrderResponse{}, err
+	}
+	caps := a.API.Capabilities()
+	if req.Type.IsConditional() && !caps.SupportsType(req.Type) {
+		resp, err := a.Stops.Submit(ctx, req)
+		if err != nil {
+			return domain.OrderResponse{}, err
+		}
+		s.reserveFunds(ctx, x, a, req, resp)
+		return resp, nil
+	}
+
+	if req.PostOnly && !caps.PostOnly {
+		if err := s.checkPostOnly(ctx, x, req); err != nil {
+			return domain.OrderResponse{}, err
+		}
+	}
+	emulateTIF := !caps.SupportsTimeInForce(req.TimeInForce)
+	if emulateTIF && req.TimeInForce == domain.TimeInForceFOK {
+		if err := s.checkFillOrKill(ctx, x, req); err != nil {
+			return domain.OrderResponse{}, err
+		}
+	}
+
+	resp, err := a.API.CreateOrder(ctx, req)
+	if err != nil {
+		return domain.OrderResponse{}, err
+	}
+	s.reserveFunds(ctx, x, a, req, resp)
+	if emulateTIF && req.Type == domain.TypeLimit {
+		resp = s.cancelRemainder(ctx, a, resp)
+	}
 	return resp, nil
 }
 
-func (s *TradingService) CancelOrder(ctx context.Context, symbol, orderID string) error {
-	err := s.exchange.CancelOrder(ctx, symbol, orderID)
+// stopChildPlacer places the child orders of a's triggered stops after
+// the checks of placeOrder. Balances are read afresh, since the OCO leg
+// a child replaces has just been cancelled.
+func (s *TradingService) stopChildPlacer(x *Exchange, a *Account) placeFunc {
+	return func(ctx context.Context, replaces string, req domain.OrderRequest) (domain.OrderResponse, error) {
+		if err := s.checkHalt(); err != nil {
+			return domain.OrderResponse{}, err
+		}
+		o := &RiskOrder{Request: req, Exchange: x.Name, Account: a.Name, x: x, a: a, replaces: replaces}
+		if err := s.cfg.Risk.run(ctx, o); err != nil {
+			return domain.OrderResponse{}, err
+		}
+		a.balances.invalidate()
+		if err := s.checkFunds(ctx, x, a, req); err != nil {
+			return domain.OrderResponse{}, err
+		}
+		resp, err := a.API.CreateOrder(ctx, req)
+		if err != nil {
+			return domain.OrderResponse{}, err
+		}
+		s.reserveFunds(ctx, x, a, req, resp)
+		return resp, nil
+	}
+}
+
+func (s *TradingService) CancelOrder(ctx context.Context, symbol domain.Symbol, orderID string) error {
+	_, a, err := s.exchanges.ResolveAccount(ctx)
+	if err != nil {
+		return err
+	}
+	if a.Stops.Owns(orderID) {
+		err = a.Stops.Cancel(ctx, orderID)
+	} else {
+		err = a.API.CancelOrder(ctx, symbol, orderID)
+	}
+	a.balances.invalidate()
 	if err != nil {
-		s.log.Error(ctx, "CancelOrder failed", ports.Fields{"error": err})
+		s.log.Error(ctx, "CancelOrder failed", ports.Fields{"account": a.Name, "error": err})
 		return fmt.Errorf("CancelOrder failed: %w", err)
 	}
 	return nil
 }
 
+func (s *TradingService) GetOrder(ctx context.Context, symbol domain.Symbol, orderID string) (domain.OrderResponse, error) {
+	_, a, err := s.exchanges.ResolveAccount(ctx)
+	if err != nil {
+		return domain.OrderResponse{}, err
+	}
+	var order domain.OrderResponse
+	if a.Stops.Owns(orderID) {
+		order, err = a.Stops.Get(ctx, orderID)
+	} else {
+		order, err = a.API.GetOrder(ctx, symbol, orderID)
+	}
+	if err != nil {
+		s.log.Error(ctx, "GetOrder failed", ports.Fields{"account": a.Name, "symbol": symbol, "orderID": orderID, "error": err})
+		return domain.OrderResponse{}, fmt.Errorf("GetOrder failed: %w", err)
+	}
+	order = a.orders.observe(ctx, order)
+	return a.tagOrder(order), nil
+}
+
+func (s *TradingService) ListOpenOrders(ctx context.Context, symbol *domain.Symbol) ([]domain.OrderResponse, error) {
+	_, a, err := s.exchanges.ResolveAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := a.API.ListOpenOrders(ctx, symbol)
+	if err != nil {
+		s.log.Error(ctx, "ListOpenOrders failed", ports.Fields{"account": a.Name, "error": err})
+		return nil, fmt.Errorf("ListOpenOrders failed: %w", err)
+	}
+	orders = append(orders, a.Stops.Open(symbol)...)
+	for i, o := range orders {
+		orders[i] = a.tagOrder(a.orders.observe(ctx, o))
+	}
+	return orders, nil
+}
+
+func (s *TradingService) GetOrderHistory(ctx context.Context, filter domain.HistoryFilter) ([]domain.OrderResponse, error) {
+	_, a, err := s.exchanges.ResolveAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := a.API.GetOrderHistory(ctx, filter)
+	if err != nil {
+		s.log.Error(ctx, "GetOrderHistory failed", ports.Fields{"account": a.Name, "error": err})
+		return nil, fmt.Errorf("GetOrderHistory failed: %w", err)
+	}
+	for i := range orders {
+		orders[i] = a.tagOrder(orders[i])
+	}
+	return orders, nil
+}
+
+func (s *TradingService) GetTrades(ctx context.Context, filter domain.HistoryFilter) ([]domain.Trade, error) {
+	_, a, err := s.exchanges.ResolveAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := a.API.GetTrades(ctx, filter)
+	if err != nil {
+		s.log.Error(ctx, "GetTrades failed", ports.Fields{"account": a.Name, "error": err})
+		return nil, fmt.Errorf("GetTrades failed: %w", err)
+	}
+	for i := range trades {
+		trades[i].Account = a.Name
+	}
+	return trades, nil
+}
+
 func (s *TradingService) GetBalance(ctx context.Context) ([]domain.Balance, error) {
-	balances, err := s.exchange.GetBalance(ctx)
+	_, a, err := s.exchanges.ResolveAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := a.API.GetBalance(ctx)
 	if err != nil {
-		s.log.Error(ctx, "GetBalance failed", ports.Fields{"error": err})
+		s.log.Error(ctx, "GetBalance failed", ports.Fields{"account": a.Name, "error": err})
 		return nil, fmt.Errorf("GetBalance failed: %w", err)
 	}
+	a.balances.store(balances)
+	for i := range balances {
+		balances[i].Account = a.Name
+	}
 	return balances, nil
 }
 
-func (s *TradingService) GetOrderBook(ctx context.Context, symbol string) (domain.OrderBook, error) {
-	book, err := s.exchange.GetOrderBook(ctx, symbol)
+func (s *TradingService) GetOrderBook(ctx context.Context, symbol domain.Symbol) (domain.OrderBook, error) {
+	x, err := s.exchanges.Resolve(ctx)
+	if err != nil {
+		return domain.OrderBook{}, err
+	}
+	book, err := x.Books.OrderBook(ctx, symbol)
 	if err != nil {
 		s.log.Error(ctx, "GetOrderBook failed", ports.Fields{"error": err})
 		return domain.OrderBook{}, fmt.Errorf("GetOrderBook failed: %w", err)
 	}
 	return book, nil
 }
+
+func (s *TradingService) GetRecentTrades(ctx context.Context, symbol domain.Symbol, limit int) ([]domain.PublicTrade, error) {
+	x, err := s.exchanges.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := x.API.GetRecentTrades(ctx, symbol, limit)
+	if err != nil {
+		s.log.Error(ctx, "GetRecentTrades failed", ports.Fields{"symbol": symbol, "error": err})
+		return nil, fmt.Errorf("GetRecentTrades failed: %w", err)
+	}
+	return trades, nil
+}
+
+func (s *TradingService) GetTicker(ctx context.Context, symbol domain.Symbol) (domain.Ticker, error) {
+	x, err := s.exchanges.Resolve(ctx)
+	if err != nil {
+		return domain.Ticker{}, err
+	}
+	ticker, err := x.API.GetTicker(ctx, symbol)
+	if err != nil {
+		s.log.Error(ctx, "GetTicker failed", ports.Fields{"symbol": symbol, "error": err})
+		return domain.Ticker{}, fmt.Errorf("GetTicker failed: %w", err)
+	}
+	return ticker, nil
+}
+
+func (s *TradingService) GetTickers(ctx context.Context) ([]domain.Ticker, error) {
+	x, err := s.exchanges.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tickers, err := x.API.GetTickers(ctx)
+	if err != nil {
+		s.log.Error(ctx, "GetTickers failed", ports.Fields{"error": err})
+		return nil, fmt.Errorf("GetTickers failed: %w", err)
+	}
+	return tickers, nil
+}
+
+func (s *TradingService) GetCandles(ctx context.Context, symbol domain.Symbol, resolution domain.Resolution, from, to time.Time) ([]domain.Candle, error) {
+	x, err := s.exchanges.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candles, err := x.API.GetCandles(ctx, symbol, resolution, from, to)
+	if err != nil {
+		s.log.Error(ctx, "GetCandles failed", ports.Fields{"symbol": symbol, "resolution": resolution, "error": err})
+		return nil, fmt.Errorf("GetCandles failed: %w", err)
+	}
+	return candles, nil
+}
+
+func (s *TradingService) GetMarkets(ctx context.Context) ([]domain.Market, error) {
+	x, err := s.exchanges.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	markets, err := x.Markets.Markets(ctx)
+	if err != nil {
+		s.log.Error(ctx, "GetMarkets failed", ports.Fields{"error": err})
+		return nil, fmt.Errorf("GetMarkets failed: %w", err)
+	}
+	return markets, nil
+}
@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// userStreamBuffer is the number of undelivered events a subscriber may
+// have queued before it is dropped.
+const userStreamBuffer = 256
+
+// pollFillsLimit is how many recent fills each poll compares.
+const pollFillsLimit = 100
+
+// PollingUserStream implements domain.UserStreamPort for exchanges without
+// a private websocket. While anyone is subscribed it polls open orders,
+// recent fills and balances, and emits whatever changed since the previous
+// poll. The first poll after the first subscription only records state.
+type PollingUserStream struct {
+	exchange domain.ExchangePort
+	interval time.Duration
+	log      ports.LoggerPort
+
+	mu   sync.Mutex
+	subs map[chan domain.UserEvent]struct{}
+	// gen changes whenever the recorded state is discarded, so a poll
+	// that started before that does not publish against stale state.
+	gen      uint64
+	seeded   bool
+	orders   map[string]domain.OrderResponse
+	fills    map[string]bool
+	balances map[string]domain.Balance
+}
+
+// NewPollingUserStream starts the poller; it runs until ctx is done.
+func NewPollingUserStream(ctx context.Context, exch domain.ExchangePort, interval time.Duration, log ports.LoggerPort) *PollingUserStream {
+	s := &PollingUserStream{
+		exchange: exch,
+		interval: interval,
+		log:      log,
+		subs:     make(map[chan domain.UserEvent]struct{}),
+	}
+	go s.run(ctx)
+	return s
+}
+
+func (s *PollingUserStream) SubscribeUser(ctx context.Context) (<-chan domain.UserEvent, error) {
+	ch := make(chan domain.UserEvent, userStreamBuffer)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.remove(ch)
+	}()
+	return ch, nil
+}
+
+// remove drops one subscriber and forgets the recorded state with the last
+// one. Callers hold s.mu.
+func (s *PollingUserStream) remove(ch chan domain.UserEvent) {
+	if _, ok := s.subs[ch]; !ok {
+		return
+	}
+	delete(s.subs, ch)
+	close(ch)
+	if len(s.subs) == 0 {
+		s.gen++
+		s.seeded = false
+		s.orders, s.fills, s.balances = nil, nil, nil
+	}
+}
+
+func (s *PollingUserStream) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(ctx)
+		}
+	}
+}
+
+func (s *PollingUserStream) poll(ctx context.Context) {
+	s.mu.Lock()
+	if len(s.subs) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	gen, seeded, prevOrders, prevFills, prevBalances := s.gen, s.seeded, s.orders, s.fills, s.balances
+	s.mu.Unlock()
+
+	open, err := s.exchange.ListOpenOrders(ctx, nil)
+	if err != nil {
+		s.log.Error(ctx, "user stream poll: open orders failed", ports.Fields{"error": err})
+		return
+	}
+	trades, err := s.exchange.GetTrades(ctx, domain.HistoryFilter{Limit: pollFillsLimit})
+	if err != nil {
+		s.log.Error(ctx, "user stream poll: fills failed", ports.Fields{"error": err})
+		return
+	}
+	balances, err := s.exchange.GetBalance(ctx)
+	if err != nil {
+		s.log.Error(ctx, "user stream poll: balances failed", ports.Fields{"error": err})
+		return
+	}
+
+	orders := make(map[string]domain.OrderResponse, len(open))
+	for _, o := range open {
+		orders[o.ID] = o
+	}
+	fills := make(map[string]bool, len(trades))
+	for _, t := range trades {
+		fills[t.ID] = true
+	}
+	byAsset := make(map[string]domain.Balance, len(balances))
+	for _, b := range balances {
+		byAsset[b.Asset] = b
+	}
+
+	var events []domain.UserEvent
+	if seeded {
+		events = s.diff(ctx, prevOrders, orders, prevFills, trades, prevBalances, balances)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gen != gen {
+		return
+	}
+	s.seeded, s.orders, s.fills, s.balances = true, orders, fills, byAsset
+	for _, ev := range events {
+		for ch := range s.subs {
+			select {
+			case ch <- ev:
+			default:
+				s.log.Error(ctx, "user subscriber lagging, closing", nil)
+				s.remove(ch)
+			}
+		}
+	}
+}
+
+// diff turns the changes between two polls into events. Orders that left
+// the open list are looked up once more to report their final state.
+func (s *PollingUserStream) diff(ctx context.Context,
+	prevOrders, orders map[string]domain.OrderResponse,
+	prevFills map[string]bool, trades []domain.Trade,
+	prevBalances map[string]domain.Balance, balances []domain.Balance,
+) []domain.UserEvent {
+	now := time.Now()
+	var events []domain.UserEvent
+	orderEvent := func(o domain.OrderResponse) {
+		events = append(events, domain.UserEvent{Type: domain.UserEventOrder, Order: &o, Time: now})
+	}
+
+	for id, o := range orders {
+		prev, ok := prevOrders[id]
+		if !ok || prev.Status != o.Status || !prev.FilledQuantity.Equal(o.FilledQuantity) {
+			orderEvent(o)
+		}
+	}
+	for id, prev := range prevOrders {
+		if _, ok := orders[id]; ok {
+			continue
+		}
+		final, err := s.exchange.GetOrder(ctx, prev.Symbol, id)
+		if err != nil {
+			s.log.Error(ctx, "user stream poll: closed order lookup failed", ports.Fields{"orderID": id, "error": err})
+			continue
+		}
+		orderEvent(final)
+	}
+
+	// Fills arrive newest first; emit them in the order they happened.
+	for i := len(trades) - 1; i >= 0; i-- {
+		if t := trades[i]; !prevFills[t.ID] {
+			events = append(events, domain.UserEvent{Type: domain.UserEventFill, Trade: &t, Time: now})
+		}
+	}
+
+	for _, b := range balances {
+		prev, ok := prevBalances[b.Asset]
+		if !ok || !prev.Free.Equal(b.Free) || !prev.Locked.Equal(b.Locked) {
+			events = append(events, domain.UserEvent{Type: domain.UserEventBalance, Balance: &b, Time: now})
+		}
+	}
+	return events
+}
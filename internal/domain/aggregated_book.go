@@ -0,0 +1,103 @@
+package domain
+
+import "sort"
+
+// VenueQuantity is one exchange's share of an aggregated price level.
+type VenueQuantity struct {
+	Exchange string
+	Quantity Decimal
+}
+
+// AggregatedLevel is the depth at one price summed across exchanges.
+// Venues is sorted by exchange name.
+type AggregatedLevel struct {
+	Price    Decimal
+	Quantity Decimal
+	Venues   []VenueQuantity
+}
+
+// AggregatedBook is one market's depth across several exchanges, bids best
+// (highest) first and asks best (lowest) first. Exchanges lists the venues
+// merged; Errors holds the reason for each venue left out. The merged book
+// may be crossed when one venue's bid is above another's ask.
+type AggregatedBook struct {
+	Symbol    Symbol
+	Bids      []AggregatedLevel
+	Asks      []AggregatedLevel
+	Exchanges []string
+	Errors    map[string]string `json:",omitempty"`
+}
+
+// MergeBooks aggregates the books of symbol by exchange name. Prices must
+// already be in the canonical quote unit, which holds for books fetched by
+// canonical symbol.
+func MergeBooks(symbol Symbol, books map[string]OrderBook) AggregatedBook {
+	out := AggregatedBook{Symbol: symbol}
+	for name := range books {
+		out.Exchanges = append(out.Exchanges, name)
+	}
+	sort.Strings(out.Exchanges)
+
+	bids := make(map[string]*AggregatedLevel)
+	asks := make(map[string]*AggregatedLevel)
+	for _, name := range out.Exchanges {
+		book := books[name]
+		addVenue(bids, name, book.Bids)
+		addVenue(asks, name, book.Asks)
+	}
+	out.Bids = sortedAggregate(bids, true)
+	out.Asks = sortedAggregate(asks, false)
+	return out
+}
+
+// BestBid returns the highest aggregated bid.
+func (b AggregatedBook) BestBid() (AggregatedLevel, bool) {
+	if len(b.Bids) == 0 {
+		return AggregatedLevel{}, false
+	}
+	return b.Bids[0], true
+}
+
+// BestAsk returns the lowest aggregated ask.
+func (b AggregatedBook) BestAsk() (AggregatedLevel, bool) {
+	if len(b.Asks) == 0 {
+		return AggregatedLevel{}, false
+	}
+	return b.Asks[0], true
+}
+
+// addVenue adds one exchange's side to levels, keyed by the price's text
+// so that equal prices of different scale meet on one level.
+func addVenue(levels map[string]*AggregatedLevel, exchange string, side []DepthLevel) {
+	for _, l := range side {
+		if !l.Quantity.IsPositive() {
+			continue
+		}
+		key := l.Price.String()
+		agg, ok := levels[key]
+		if !ok {
+			agg = &AggregatedLevel{Price: l.Price}
+			levels[key] = agg
+		}
+		agg.Quantity = agg.Quantity.Add(l.Quantity)
+		if n := len(agg.Venues); n > 0 && agg.Venues[n-1].Exchange == exchange {
+			agg.Venues[n-1].Quantity = agg.Venues[n-1].Quantity.Add(l.Quantity)
+		} else {
+			agg.Venues = append(agg.Venues, VenueQuantity{Exchange: exchange, Quantity: l.Quantity})
+		}
+	}
+}
+
+func sortedAggregate(levels map[string]*AggregatedLevel, desc bool) []AggregatedLevel {
+	out := make([]AggregatedLevel, 0, len(levels))
+	for _, l := range levels {
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if desc {
+			return out[i].Price.GreaterThan(out[j].Price)
+		}
+		return out[i].Price.LessThan(out[j].Price)
+	})
+	return out
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// ArbitrageOpportunity is a price difference for one market between two
+// exchanges: buying Quantity on BuyExchange up to BuyPrice and selling it
+// on SellExchange down to SellPrice. Cost is the quote paid including the
+// taker fee, Proceeds the quote received after it, and TransferCost the
+// quote value of moving the bought asset to the selling exchange.
+// NetSpread is NetProfit as a fraction of Cost.
+type ArbitrageOpportunity struct {
+	Symbol       Symbol
+	BuyExchange  string
+	SellExchange string
+	BuyPrice     Decimal
+	SellPrice    Decimal
+	Quantity     Decimal
+	Cost         Decimal
+	Proceeds     Decimal
+	TransferCost Decimal
+	NetProfit    Decimal
+	NetSpread    Decimal
+	SeenAt       time.Time
+}
+
+// ArbitrageVenue is one exchange's book and taker fee rate as input to
+// FindArbitrage.
+type ArbitrageVenue struct {
+	Exchange string
+	Book     OrderBook
+	TakerFee Decimal
+}
+
+// netSpreadScale is the precision of ArbitrageOpportunity.NetSpread.
+const netSpreadScale = 8
+
+// FindArbitrage matches buy's asks against sell's bids for as long as a
+// bid after fees still pays more than the ask after fees, and reports the
+// result once transferCost, in units of the base asset, is paid out of it.
+// It reports false when no level pair is profitable before the transfer
+// cost; the opportunity may still have a negative NetProfit.
+func FindArbitrage(symbol Symbol, buy, sell ArbitrageVenue, transferCost Decimal) (ArbitrageOpportunity, bool) {
+	one := NewDecimalFromInt(1)
+	buyFactor := one.Add(buy.TakerFee)
+	sellFactor := one.Sub(sell.TakerFee)
+
+	asks := sortedLevels(buy.Book.Asks, false)
+	bids := sortedLevels(sell.Book.Bids, true)
+
+	out := ArbitrageOpportunity{Symbol: symbol, BuyExchange: buy.Exchange, SellExchange: sell.Exchange}
+	for i, j := 0, 0; i < len(asks) && j < len(bids); {
+		ask, bid := &asks[i], &bids[j]
+		if !bid.Price.Mul(sellFactor).GreaterThan(ask.Price.Mul(buyFactor)) {
+			break
+		}
+		qty := MinDecimal(ask.Quantity, bid.Quantity)
+		out.Quantity = out.Quantity.Add(qty)
+		out.Cost = out.Cost.Add(qty.Mul(ask.Price).Mul(buyFactor))
+		out.Proceeds = out.Proceeds.Add(qty.Mul(bid.Price).Mul(sellFactor))
+		out.BuyPrice, out.SellPrice = ask.Price, bid.Price
+
+		ask.Quantity = ask.Quantity.Sub(qty)
+		bid.Quantity = bid.Quantity.Sub(qty)
+		if !ask.Quantity.IsPositive() {
+			i++
+		}
+		if !bid.Quantity.IsPositive() {
+			j++
+		}
+	}
+	if !out.Quantity.IsPositive() || !out.Cost.IsPositive() {
+		return ArbitrageOpportunity{}, false
+	}
+
+	out.TransferCost = transferCost.Mul(out.SellPrice)
+	out.NetProfit = out.Proceeds.Sub(out.Cost).Sub(out.TransferCost)
+	out.NetSpread = out.NetProfit.Div(out.Cost, netSpreadScale, RoundHalfEven)
+	return out, true
+}
+
+// sortedLevels returns a copy of side with usable levels only, those with
+// a positive price and quantity, best first.
+func sortedLevels(side []DepthLevel, desc bool) []DepthLevel {
+	out := make([]DepthLevel, 0, len(side))
+	for _, l := range side {
+		if l.Quantity.IsPositive() && l.Price.IsPositive() {
+			out = append(out, l)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if desc {
+			return out[i].Price.GreaterThan(out[j].Price)
+		}
+		return out[i].Price.LessThan(out[j].Price)
+	})
+	return out
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resolution is the width of a candle.
+type Resolution string
+
+const (
+	Resolution1m  Resolution = "1m"
+	Resolution5m  Resolution = "5m"
+	Resolution15m Resolution = "15m"
+	Resolution30m Resolution = "30m"
+	Resolution1h  Resolution = "1h"
+	Resolution4h  Resolution = "4h"
+	Resolution1d  Resolution = "1d"
+	Resolution1w  Resolution = "1w"
+)
+
+var resolutionDurations = map[Resolution]time.Duration{
+	Resolution1m:  time.Minute,
+	Resolution5m:  5 * time.Minute,
+	Resolution15m: 15 * time.Minute,
+	Resolution30m: 30 * time.Minute,
+	Resolution1h:  time.Hour,
+	Resolution4h:  4 * time.Hour,
+	Resolution1d:  24 * time.Hour,
+	Resolution1w:  7 * 24 * time.Hour,
+}
+
+func ParseResolution(s string) (Resolution, error) {
+	r := Resolution(s)
+	if _, ok := resolutionDurations[r]; !ok {
+		return "", fmt.Errorf("invalid resolution %q: want one of 1m, 5m, 15m, 30m, 1h, 4h, 1d, 1w", s)
+	}
+	return r, nil
+}
+
+// Duration returns the width of one candle, or zero for an unknown
+// resolution.
+func (r Resolution) Duration() time.Duration {
+	return resolutionDurations[r]
+}
+
+// Windows splits [from, to] into consecutive ranges of at most maxBars
+// candles each, for exchanges that cap the bars returned per request.
+func (r Resolution) Windows(from, to time.Time, maxBars int) []TimeRange {
+	span := time.Duration(maxBars) * r.Duration()
+	if span <= 0 {
+		return []TimeRange{{From: from, To: to}}
+	}
+	var out []TimeRange
+	for start := from; !start.After(to); start = start.Add(span) {
+		end := start.Add(span - time.Second)
+		if end.After(to) {
+			end = to
+		}
+		out = append(out, TimeRange{From: start, To: end})
+	}
+	return out
+}
+
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Candle is one OHLCV bar; Time is when the bar opens.
+type Candle struct {
+	Time   time.Time
+	Open   Decimal
+	High   Decimal
+	Low    Decimal
+	Close  Decimal
+	Volume Decimal
+}
@@ -0,0 +1,368 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode selects how digits are dropped when a Decimal is rounded
+// to fewer places or to a step size.
+type RoundingMode int
+
+const (
+	// RoundDown rounds toward zero (truncation).
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor
+	// RoundCeiling rounds toward positive infinity.
+	RoundCeiling
+	// RoundHalfUp rounds to nearest, ties away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to nearest, ties to the even neighbour.
+	RoundHalfEven
+)
+
+// Decimal is an exact fixed-point number: coef × 10^-scale.
+// The zero value is 0 and ready to use. Decimals are immutable.
+type Decimal struct {
+	coef  *big.Int
+	scale int32
+}
+
+var Zero = Decimal{}
+
+// maxExponent bounds the exponent accepted by ParseDecimal and maxScale
+// the resulting scale either way, so that input such as "1e2000000000"
+// is rejected before it is expanded.
+const (
+	maxExponent = 1 << 10
+	maxScale    = 1 << 20
+)
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+func NewDecimal(coef int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(big.NewInt(coef), pow10(-scale))}
+	}
+	return Decimal{coef: big.NewInt(coef), scale: scale}
+}
+
+func NewDecimalFromInt(v int64) Decimal {
+	return NewDecimal(v, 0)
+}
+
+// NewDecimalFromFloat converts f using its shortest exact decimal representation.
+func NewDecimalFromFloat(f float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Zero
+	}
+	return d
+}
+
+// ParseDecimal parses plain ("-12.345") and exponent ("1.5e-8") notation.
+func ParseDecimal(s string) (Decimal, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, fmt.Errorf("invalid decimal %q", orig)
+	}
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Zero, fmt.Errorf("invalid decimal %q", orig)
+		}
+		if e > maxExponent || e < -maxExponent {
+			return Zero, fmt.Errorf("invalid decimal %q: exponent out of range", orig)
+		}
+		exp = e
+		s = s[:i]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	sign := ""
+	if intPart != "" && (intPart[0] == '-' || intPart[0] == '+') {
+		sign, intPart = intPart[:1], intPart[1:]
+	}
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Zero, fmt.Errorf("invalid decimal %q", orig)
+	}
+
+	coef, ok := new(big.Int).SetString(sign+intPart+fracPart, 10)
+	if !ok {
+		return Zero, fmt.Errorf("invalid decimal %q", orig)
+	}
+	scale := int64(len(fracPart)) - exp
+	if scale > maxScale || scale < -maxScale {
+		return Zero, fmt.Errorf("invalid decimal %q: scale out of range", orig)
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the coefficient of d expressed at the larger scale s.
+func (d Decimal) rescale(s int32) *big.Int {
+	if s == d.scale {
+		return d.int()
+	}
+	return new(big.Int).Mul(d.int(), pow10(s-d.scale))
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	s := a.scale
+	if b.scale > s {
+		s = b.scale
+	}
+	return a.rescale(s), b.rescale(s), s
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	x, y, s := align(d, o)
+	return Decimal{coef: new(big.Int).Add(x, y), scale: s}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	x, y, s := align(d, o)
+	return Decimal{coef: new(big.Int).Sub(x, y), scale: s}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.int(), o.int()), scale: d.scale + o.scale}
+}
+
+// Div returns d / o rounded to scale decimal places with mode.
+// It panics if o is zero.
+func (d Decimal) Div(o Decimal, scale int32, mode RoundingMode) Decimal {
+	if o.IsZero() {
+		panic("domain: decimal division by zero")
+	}
+	num := new(big.Int).Mul(d.int(), pow10(o.scale+scale))
+	den := new(big.Int).Mul(o.int(), pow10(d.scale))
+	return Decimal{coef: roundQuo(num, den, mode), scale: scale}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+func (d Decimal) Sign() int { return d.int().Sign() }
+
+func (d Decimal) IsZero() bool { return d.Sign() == 0 }
+
+func (d Decimal) IsPositive() bool { return d.Sign() > 0 }
+
+func (d Decimal) IsNegative() bool { return d.Sign() < 0 }
+
+// Scale reports the number of digits after the decimal point.
+func (d Decimal) Scale() int32 { return d.scale }
+
+func (d Decimal) Cmp(o Decimal) int {
+	x, y, _ := align(d, o)
+	return x.Cmp(y)
+}
+
+func (d Decimal) Equal(o Decimal) bool { return d.Cmp(o) == 0 }
+
+func (d Decimal) LessThan(o Decimal) bool { return d.Cmp(o) < 0 }
+
+func (d Decimal) GreaterThan(o Decimal) bool { return d.Cmp(o) > 0 }
+
+func MinDecimal(a, b Decimal) Decimal {
+	if b.LessThan(a) {
+		return b
+	}
+	return a
+}
+
+func MaxDecimal(a, b Decimal) Decimal {
+	if b.GreaterThan(a) {
+		return b
+	}
+	return a
+}
+
+// Round returns d with at most places digits after the point, using mode.
+// Increasing the scale never changes the value.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if places >= d.scale {
+		return d
+	}
+	if places < 0 {
+		places = 0
+	}
+	q := roundQuo(d.int(), pow10(d.scale-places), mode)
+	return Decimal{coef: q, scale: places}
+}
+
+// RoundToStep returns the multiple of step nearest to d according to mode.
+// A non-positive step returns d unchanged.
+func (d Decimal) RoundToStep(step Decimal, mode RoundingMode) Decimal {
+	if !step.IsPositive() {
+		return d
+	}
+	n := d.Div(step, 0, mode)
+	return n.Mul(step)
+}
+
+// IsMultipleOf reports whether d is an exact multiple of step.
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	if !step.IsPositive() {
+		return true
+	}
+	return d.RoundToStep(step, RoundDown).Equal(d)
+}
+
+// roundQuo divides num by den and rounds the quotient to an integer with mode.
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	neg := (num.Sign() < 0) != (den.Sign() < 0)
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	c := half.Cmp(new(big.Int).Abs(den))
+
+	var away bool
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundFloor:
+		away = neg
+	case RoundCeiling:
+		away = !neg
+	case RoundHalfUp:
+		away = c >= 0
+	case RoundHalfEven:
+		away = c > 0 || (c == 0 && q.Bit(0) == 1)
+	}
+	if away {
+		if neg {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return q
+}
+
+// String renders d in plain notation without trailing fractional zeros.
+func (d Decimal) String() string {
+	s := d.int().String()
+	if d.scale == 0 {
+		return s
+	}
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if pad := int(d.scale) + 1 - len(s); pad > 0 {
+		s = strings.Repeat("0", pad) + s
+	}
+	i := len(s) - int(d.scale)
+	intPart, frac := s[:i], strings.TrimRight(s[i:], "0")
+	if frac != "" {
+		intPart += "." + frac
+	}
+	if neg && intPart != "0" {
+		intPart = "-" + intPart
+	}
+	return intPart
+}
+
+// Float64 returns the nearest float64; use it only for display and logging.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// MarshalJSON encodes d as a JSON string so no precision is lost by clients
+// that decode numbers into floating point.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON accepts a JSON string, a JSON number or null.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return fmt.Errorf("invalid decimal %s", data)
+		}
+		if s == "" {
+			*d = Zero
+			return nil
+		}
+		data = []byte(s)
+	}
+	v, err := ParseDecimal(string(data))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,302 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  string
+		scale int32
+	}{
+		{"0", "0", 0},
+		{"12.345", "12.345", 3},
+		{"-12.345", "-12.345", 3},
+		{"+7", "7", 0},
+		{" 1.50 ", "1.5", 2},
+		{".5", "0.5", 1},
+		{"5.", "5", 0},
+		{"-0.0", "0", 1},
+		{"1.5e-8", "0.000000015", 9},
+		{"1.5E3", "1500", 0},
+		{"-2e+2", "-200", 0},
+		{"123e-2", "1.23", 2},
+		{"0.001e3", "1", 0},
+		{"1e1024", "1" + strings.Repeat("0", 1024), 0},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789", 9},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q): %v", tt.in, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		if d.Scale() != tt.scale {
+			t.Errorf("ParseDecimal(%q) scale = %d, want %d", tt.in, d.Scale(), tt.scale)
+		}
+	}
+}
+
+func TestParseDecimalInvalid(t *testing.T) {
+	for _, in := range []string{
+		"", " ", "-", "+", ".", "-.", "abc", "1.2.3", "1,5", "--1", "+-1", "1-",
+		"e5", "1e", "1e+", "1e1.5", "1ee2", "0x10", "NaN", "Inf",
+		"1e1025", "1e-1025", "1e2000000000", "1e-2000000000", "1e99999999999",
+	} {
+		if d, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) = %s, want error", in, d)
+		}
+	}
+}
+
+func TestParseDecimalScaleLimit(t *testing.T) {
+	long := "0." + strings.Repeat("1", maxScale)
+	if _, err := ParseDecimal(long); err != nil {
+		t.Errorf("scale %d: %v", maxScale, err)
+	}
+	if _, err := ParseDecimal(long + "1"); err == nil {
+		t.Errorf("scale %d: want error", maxScale+1)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a, b := MustParseDecimal("1.25"), MustParseDecimal("-0.5")
+	checks := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"add", a.Add(b), "0.75"},
+		{"sub", a.Sub(b), "1.75"},
+		{"mul", a.Mul(b), "-0.625"},
+		{"neg", b.Neg(), "0.5"},
+		{"abs", b.Abs(), "0.5"},
+		{"zero add", Zero.Add(a), "1.25"},
+		{"min", MinDecimal(a, b), "-0.5"},
+		{"max", MaxDecimal(a, b), "1.25"},
+	}
+	for _, c := range checks {
+		if c.got.String() != c.want {
+			t.Errorf("%s = %s, want %s", c.name, c.got, c.want)
+		}
+	}
+	if !MustParseDecimal("1.10").Equal(MustParseDecimal("1.1")) {
+		t.Error("1.10 != 1.1")
+	}
+	if Zero.Sign() != 0 || !Zero.IsZero() || b.Sign() != -1 || !a.IsPositive() || !b.IsNegative() {
+		t.Error("sign checks failed")
+	}
+}
+
+var roundingModes = []struct {
+	name string
+	mode RoundingMode
+}{
+	{"Down", RoundDown},
+	{"Up", RoundUp},
+	{"Floor", RoundFloor},
+	{"Ceiling", RoundCeiling},
+	{"HalfUp", RoundHalfUp},
+	{"HalfEven", RoundHalfEven},
+}
+
+func TestDecimalRound(t *testing.T) {
+	// want holds the result for each mode in roundingModes order.
+	tests := []struct {
+		in   string
+		want [6]string
+	}{
+		{"2.5", [6]string{"2", "3", "2", "3", "3", "2"}},
+		{"3.5", [6]string{"3", "4", "3", "4", "4", "4"}},
+		{"-2.5", [6]string{"-2", "-3", "-3", "-2", "-3", "-2"}},
+		{"2.4", [6]string{"2", "3", "2", "3", "2", "2"}},
+		{"2.6", [6]string{"2", "3", "2", "3", "3", "3"}},
+		{"-2.6", [6]string{"-2", "-3", "-3", "-2", "-3", "-3"}},
+		{"2", [6]string{"2", "2", "2", "2", "2", "2"}},
+	}
+	for _, tt := range tests {
+		for i, m := range roundingModes {
+			if got := MustParseDecimal(tt.in).Round(0, m.mode).String(); got != tt.want[i] {
+				t.Errorf("Round(%s, 0, %s) = %s, want %s", tt.in, m.name, got, tt.want[i])
+			}
+		}
+	}
+
+	d := MustParseDecimal("1.23456")
+	if got := d.Round(3, RoundHalfUp); got.String() != "1.235" || got.Scale() != 3 {
+		t.Errorf("Round(1.23456, 3) = %s scale %d", got, got.Scale())
+	}
+	if got := d.Round(8, RoundDown); got.Scale() != d.Scale() {
+		t.Errorf("Round to more places changed scale to %d", got.Scale())
+	}
+	if got := MustParseDecimal("15.7").Round(-1, RoundDown); got.String() != "15" {
+		t.Errorf("Round(15.7, -1) = %s, want 15", got)
+	}
+}
+
+func TestDecimalDiv(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		scale int32
+		want  [6]string
+	}{
+		{"1", "3", 2, [6]string{"0.33", "0.34", "0.33", "0.34", "0.33", "0.33"}},
+		{"2", "3", 2, [6]string{"0.66", "0.67", "0.66", "0.67", "0.67", "0.67"}},
+		{"-1", "3", 2, [6]string{"-0.33", "-0.34", "-0.34", "-0.33", "-0.33", "-0.33"}},
+		{"1", "-8", 2, [6]string{"-0.12", "-0.13", "-0.13", "-0.12", "-0.13", "-0.12"}},
+		{"0.15", "1", 1, [6]string{"0.1", "0.2", "0.1", "0.2", "0.2", "0.2"}},
+		{"10", "0.25", 0, [6]string{"40", "40", "40", "40", "40", "40"}},
+	}
+	for _, tt := range tests {
+		a, b := MustParseDecimal(tt.a), MustParseDecimal(tt.b)
+		for i, m := range roundingModes {
+			got := a.Div(b, tt.scale, m.mode)
+			if got.String() != tt.want[i] {
+				t.Errorf("%s / %s (%d, %s) = %s, want %s", tt.a, tt.b, tt.scale, m.name, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestDecimalDivByZeroPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Div by zero did not panic")
+		}
+	}()
+	NewDecimalFromInt(1).Div(Zero, 2, RoundDown)
+}
+
+func TestDecimalRoundToStep(t *testing.T) {
+	tests := []struct {
+		in, step string
+		mode     RoundingMode
+		want     string
+	}{
+		{"1.237", "0.01", RoundDown, "1.23"},
+		{"1.237", "0.01", RoundUp, "1.24"},
+		{"1.235", "0.01", RoundHalfEven, "1.24"},
+		{"1.225", "0.01", RoundHalfEven, "1.22"},
+		{"-1.237", "0.01", RoundFloor, "-1.24"},
+		{"-1.237", "0.01", RoundCeiling, "-1.23"},
+		{"17", "5", RoundDown, "15"},
+		{"17", "5", RoundHalfUp, "15"},
+		{"17.5", "5", RoundHalfUp, "20"},
+		{"0.7", "0.25", RoundDown, "0.5"},
+		{"1.237", "0", RoundDown, "1.237"},
+		{"1.237", "-0.01", RoundDown, "1.237"},
+	}
+	for _, tt := range tests {
+		got := MustParseDecimal(tt.in).RoundToStep(MustParseDecimal(tt.step), tt.mode)
+		if !got.Equal(MustParseDecimal(tt.want)) {
+			t.Errorf("RoundToStep(%s, %s, %d) = %s, want %s", tt.in, tt.step, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalIsMultipleOf(t *testing.T) {
+	tests := []struct {
+		in, step string
+		want     bool
+	}{
+		{"1.25", "0.05", true},
+		{"1.26", "0.05", false},
+		{"0", "0.01", true},
+		{"-0.75", "0.25", true},
+		{"100", "7", false},
+		{"1.2300", "0.01", true},
+		{"1.23", "0", true},
+	}
+	for _, tt := range tests {
+		if got := MustParseDecimal(tt.in).IsMultipleOf(MustParseDecimal(tt.step)); got != tt.want {
+			t.Errorf("%s.IsMultipleOf(%s) = %v, want %v", tt.in, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	type payload struct {
+		Price Decimal  `json:"price"`
+		Qty   *Decimal `json:"qty"`
+	}
+
+	out, err := json.Marshal(payload{Price: MustParseDecimal("0.000000015"), Qty: ptrDecimal(MustParseDecimal("-3.50"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"price":"0.000000015","qty":"-3.5"}`; string(out) != want {
+		t.Fatalf("Marshal = %s, want %s", out, want)
+	}
+	var back payload
+	if err := json.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Price.String() != "0.000000015" || back.Qty == nil || back.Qty.String() != "-3.5" {
+		t.Errorf("round trip = %+v", back)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"price":"12.5"}`, "12.5"},
+		{`{"price":12.5}`, "12.5"},
+		{`{"price":1e-3}`, "0.001"},
+		{`{"price":""}`, "0"},
+		{`{"price":null}`, "0"},
+		{`{"price": "  7 "}`, "7"},
+		{`{"price":0.1000000000000000055511151231257827}`, "0.1000000000000000055511151231257827"},
+	}
+	for _, tt := range tests {
+		var p payload
+		if err := json.Unmarshal([]byte(tt.in), &p); err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.in, err)
+			continue
+		}
+		if p.Price.String() != tt.want {
+			t.Errorf("Unmarshal(%s) = %s, want %s", tt.in, p.Price, tt.want)
+		}
+	}
+
+	var p payload
+	if err := json.Unmarshal([]byte(`{"qty":null}`), &p); err != nil || p.Qty != nil {
+		t.Errorf("null pointer = %v, %v", p.Qty, err)
+	}
+
+	for _, in := range []string{`{"price":"abc"}`, `{"price":true}`, `{"price":"1e2000000000"}`, `{"price":[1]}`} {
+		var p payload
+		if err := json.Unmarshal([]byte(in), &p); err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want error", in, p.Price)
+		}
+	}
+}
+
+func TestDecimalText(t *testing.T) {
+	var d Decimal
+	if err := d.UnmarshalText([]byte("42.10")); err != nil {
+		t.Fatal(err)
+	}
+	text, err := d.MarshalText()
+	if err != nil || string(text) != "42.1" {
+		t.Errorf("MarshalText = %s, %v", text, err)
+	}
+	if err := d.UnmarshalText([]byte("x")); err == nil {
+		t.Error("UnmarshalText(x) succeeded")
+	}
+}
+
+func TestDecimalFromFloat(t *testing.T) {
+	for f, want := range map[float64]string{0.1: "0.1", 1e-7: "0.0000001", -2.5: "-2.5", 1e21: "1000000000000000000000"} {
+		if got := NewDecimalFromFloat(f).String(); got != want {
+			t.Errorf("NewDecimalFromFloat(%v) = %s, want %s", f, got, want)
+		}
+	}
+}
+
+func ptrDecimal(d Decimal) *Decimal { return &d }
@@ -1,13 +1,87 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// Capabilities lists the optional order features an exchange supports
+// natively. Anything not listed is emulated by the application layer.
+type Capabilities struct {
+	StopMarket bool
+	StopLimit  bool
+	OCO        bool
+	IOC        bool
+	FOK        bool
+	PostOnly   bool
+}
+
+// SupportsTimeInForce reports whether tif can be sent as-is. GTC is the
+// default everywhere.
+func (c Capabilities) SupportsTimeInForce(tif TimeInForce) bool {
+	switch tif {
+	case "", TimeInForceGTC:
+		return true
+	case TimeInForceIOC:
+		return c.IOC
+	case TimeInForceFOK:
+		return c.FOK
+	}
+	return false
+}
+
+// SupportsType reports whether orders of type t can be sent as-is.
+func (c Capabilities) SupportsType(t OrderType) bool {
+	switch t {
+	case TypeMarket, TypeLimit:
+		return true
+	case TypeStopMarket:
+		return c.StopMarket
+	case TypeStopLimit:
+		return c.StopLimit
+	case TypeOCO:
+		return c.OCO
+	}
+	return false
+}
 
 type ExchangePort interface {
+	Capabilities() Capabilities
+
 	CreateOrder(ctx context.Context, req OrderRequest) (OrderResponse, error)
 
-	CancelOrder(ctx context.Context, symbol, orderID string) error
+	CancelOrder(ctx context.Context, symbol Symbol, orderID string) error
+
+	GetOrder(ctx context.Context, symbol Symbol, orderID string) (OrderResponse, error)
+
+	// ListOpenOrders returns resting orders, for every market when symbol is nil.
+	ListOpenOrders(ctx context.Context, symbol *Symbol) ([]OrderResponse, error)
+
+	// GetOrderHistory returns closed orders, newest first.
+	GetOrderHistory(ctx context.Context, filter HistoryFilter) ([]OrderResponse, error)
+
+	// GetTrades returns our fills, newest first.
+	GetTrades(ctx context.Context, filter HistoryFilter) ([]Trade, error)
 
 	GetBalance(ctx context.Context) ([]Balance, error)
 
-	GetOrderBook(ctx context.Context, symbol string) (OrderBook, error)
+	GetOrderBook(ctx context.Context, symbol Symbol) (OrderBook, error)
+
+	// GetRecentTrades returns up to limit public trades, newest first.
+	GetRecentTrades(ctx context.Context, symbol Symbol, limit int) ([]PublicTrade, error)
+
+	GetMarkets(ctx context.Context) ([]Market, error)
+
+	GetTicker(ctx context.Context, symbol Symbol) (Ticker, error)
+
+	GetTickers(ctx context.Context) ([]Ticker, error)
+
+	// GetCandles returns the bars opening within [from, to], oldest first.
+	GetCandles(ctx context.Context, symbol Symbol, resolution Resolution, from, to time.Time) ([]Candle, error)
+}
+
+// BulkCanceler is implemented by exchanges that can cancel every open
+// order (optionally for one symbol) in a single request.
+type BulkCanceler interface {
+	CancelAll(ctx context.Context, symbol *Symbol) ([]CancelResult, error)
 }
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// HaltState is the trading kill switch. While Halted no new orders are
+// sent; cancels still are. By names who last changed the state and Since
+// when.
+type HaltState struct {
+	Halted bool
+	Reason string `json:",omitempty"`
+	By     string `json:",omitempty"`
+	Since  time.Time
+}
+
+// HaltStorePort keeps the halt state across restarts.
+type HaltStorePort interface {
+	// LoadHalt returns the saved state; ok is false when none was saved.
+	LoadHalt(ctx context.Context) (state HaltState, ok bool, err error)
+
+	SaveHalt(ctx context.Context, state HaltState) error
+}
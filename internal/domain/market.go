@@ -0,0 +1,84 @@
+package domain
+
+import "fmt"
+
+// Market describes the trading rules of a single exchange market.
+// A zero TickSize, StepSize, MinQuantity or MinNotional means the
+// exchange does not publish that rule.
+type Market struct {
+	Symbol      Symbol
+	TickSize    Decimal
+	StepSize    Decimal
+	MinQuantity Decimal
+	MinNotional Decimal
+	Tradable    bool
+}
+
+// Rules reported by OrderValidationError.
+const (
+	RuleUnknownMarket = "unknown_market"
+	RuleOrderType     = "order_type"
+	RuleMarketClosed  = "market_closed"
+	RulePriceRequired = "price_required"
+	RuleTickSize      = "tick_size"
+	RuleLotSize       = "lot_size"
+	RuleMinQuantity   = "min_quantity"
+	RuleMinNotional   = "min_notional"
+	RuleTimeInForce   = "time_in_force"
+	RulePostOnly      = "post_only"
+	RuleFillOrKill    = "fill_or_kill"
+	RuleRouting       = "routing"
+	RuleSide          = "side"
+	RulePrice         = "price"
+)
+
+// OrderValidationError reports an order that breaks a market rule and was
+// rejected before reaching the exchange.
+type OrderValidationError struct {
+	Rule   string
+	Field  string
+	Symbol Symbol
+	Value  Decimal
+	Limit  Decimal
+}
+
+func (e *OrderValidationError) Error() string {
+	switch e.Rule {
+	case RuleUnknownMarket:
+		return fmt.Sprintf("%s: unknown market %q", e.Rule, e.Symbol)
+	case RuleMarketClosed:
+		return fmt.Sprintf("%s: market %q is not tradable", e.Rule, e.Symbol)
+	case RuleOrderType:
+		return fmt.Sprintf("%s: unsupported order type", e.Rule)
+	case RulePriceRequired:
+		return fmt.Sprintf("%s: %s is required for this order type", e.Rule, e.Field)
+	case RuleTimeInForce:
+		return fmt.Sprintf("%s: %s cannot be used with this order", e.Rule, e.Field)
+	case RulePostOnly:
+		if e.Value.IsZero() {
+			return fmt.Sprintf("%s: post-only is not possible for this order", e.Rule)
+		}
+		return fmt.Sprintf("%s: price %s would take liquidity at %s", e.Rule, e.Value, e.Limit)
+	case RuleFillOrKill:
+		return fmt.Sprintf("%s: only %s of %s is available at the limit price", e.Rule, e.Limit, e.Value)
+	case RuleRouting:
+		if e.Field == "Quantity" {
+			return fmt.Sprintf("%s: no exchange can fill any of the order", e.Rule)
+		}
+		return fmt.Sprintf("%s: %s cannot be used with smart routing", e.Rule, e.Field)
+	case RuleSide:
+		return fmt.Sprintf("%s: order side must be %s or %s", e.Rule, SideBuy, SideSell)
+	case RulePrice:
+		return fmt.Sprintf("%s: %s %s must be positive", e.Rule, e.Field, e.Value)
+	case RuleTickSize, RuleLotSize:
+		return fmt.Sprintf("%s: %s %s is not a multiple of %s", e.Rule, e.Field, e.Value, e.Limit)
+	default:
+		return fmt.Sprintf("%s: %s %s is below the minimum %s", e.Rule, e.Field, e.Value, e.Limit)
+	}
+}
+
+// Precision returns 10^-places, the step that corresponds to a number of
+// decimal places as published by most exchanges.
+func Precision(places int32) Decimal {
+	return NewDecimal(1, places)
+}
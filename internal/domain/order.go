@@ -12,44 +12,214 @@ const (
 type OrderType string
 
 const (
-	TypeMarket OrderType = "MARKET"
-	TypeLimit  OrderType = "LIMIT"
+	TypeMarket     OrderType = "MARKET"
+	TypeLimit      OrderType = "LIMIT"
+	TypeStopMarket OrderType = "STOP_MARKET"
+	TypeStopLimit  OrderType = "STOP_LIMIT"
+	TypeOCO        OrderType = "OCO"
 )
 
+// TimeInForce controls how long an order may rest. The empty value means GTC.
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+	TimeInForceIOC TimeInForce = "IOC"
+	TimeInForceFOK TimeInForce = "FOK"
+)
+
+// IsImmediate reports whether unfilled quantity must not rest on the book.
+func (t TimeInForce) IsImmediate() bool {
+	return t == TimeInForceIOC || t == TimeInForceFOK
+}
+
+// IsConditional reports whether the order waits for a trigger price.
+func (t OrderType) IsConditional() bool {
+	return t == TypeStopMarket || t == TypeStopLimit || t == TypeOCO
+}
+
+// OrderRequest describes an order to place.
+//
+// Stop orders become a market (STOP_MARKET) or a limit order at Price
+// (STOP_LIMIT) once the market trades through TriggerPrice: upwards for
+// buys, downwards for sells. An OCO order rests as a limit order at Price
+// together with a stop at TriggerPrice; whichever executes first cancels
+// the other. The OCO stop leg is a limit order at StopLimitPrice when set
+// and a market order otherwise.
+//
+// PostOnly orders must add liquidity: they are rejected rather than
+// allowed to match on arrival.
 type OrderRequest struct {
-	Symbol    string
-	Side      OrderSide
-	Type      OrderType
-	Quantity  float64
-	Price     *float64
-	ClientID  *string
-	Timestamp time.Time
+	Symbol         Symbol
+	Side           OrderSide
+	Type           OrderType
+	Quantity       Decimal
+	Price          *Decimal
+	TriggerPrice   *Decimal
+	StopLimitPrice *Decimal
+	TimeInForce    TimeInForce
+	PostOnly       bool
+	ClientID       *string
+	Timestamp      time.Time
+}
+
+// Triggered reports whether book has traded through the request's trigger price.
+func (r OrderRequest) Triggered(book OrderBook) bool {
+	if r.TriggerPrice == nil {
+		return false
+	}
+	if r.Side == SideBuy {
+		ask, ok := book.BestAsk()
+		return ok && ask.Price.Cmp(*r.TriggerPrice) >= 0
+	}
+	bid, ok := book.BestBid()
+	return ok && bid.Price.Cmp(*r.TriggerPrice) <= 0
 }
 
+// OrderResponse is an order as last seen on the exchange. RawStatus keeps
+// the exchange's own status string next to the normalized Status. Account
+// names the account the order belongs to; like on CancelResult, Balance,
+// Trade and UserEvent it is set by the application layer, not adapters.
+// Exchange is only set on the children of a routed order, which may each
+// sit on a different exchange.
 type OrderResponse struct {
-	ID        string
-	Symbol    string
-	Side      OrderSide
-	Type      OrderType
-	Quantity  float64
-	Price     float64
-	Status    string
-	Timestamp time.Time
+	ID             string
+	Symbol         Symbol
+	Side           OrderSide
+	Type           OrderType
+	Quantity       Decimal
+	Price          Decimal
+	Status         OrderStatus
+	RawStatus      string
+	FilledQuantity Decimal
+	AvgPrice       Decimal
+	Timestamp      time.Time
+	Account        string `json:",omitempty"`
+	Exchange       string `json:",omitempty"`
+
+	// Children holds the orders placed on behalf of this one, such as the
+	// legs of an emulated stop or OCO order or the per-exchange parts of a
+	// routed order.
+	Children []OrderResponse `json:",omitempty"`
+}
+
+// CancelResult reports the outcome of cancelling one order in a bulk
+// cancel. Error is empty on success. Exchange is only set when the cancel
+// spanned several exchanges.
+type CancelResult struct {
+	OrderID  string
+	Symbol   Symbol
+	Canceled bool
+	Error    string `json:",omitempty"`
+	Account  string `json:",omitempty"`
+	Exchange string `json:",omitempty"`
+}
+
+// PlacementResult reports the outcome of one order in a batch, in request
+// order. RolledBack marks an order that was placed and then cancelled
+// because another order of an all-or-nothing batch failed.
+type PlacementResult struct {
+	Index      int
+	Order      *OrderResponse `json:",omitempty"`
+	Error      string         `json:",omitempty"`
+	RolledBack bool           `json:",omitempty"`
 }
 
 type Balance struct {
-	Asset  string
-	Free   float64
-	Locked float64
+	Asset   string
+	Free    Decimal
+	Locked  Decimal
+	Account string `json:",omitempty"`
 }
 
 type DepthLevel struct {
-	Price    float64
-	Quantity float64
+	Price    Decimal
+	Quantity Decimal
 }
 
 type OrderBook struct {
-	Symbol string
+	Symbol Symbol
 	Bids   []DepthLevel
 	Asks   []DepthLevel
 }
+
+// BestBid returns the highest bid. It does not assume Bids is sorted.
+func (b OrderBook) BestBid() (DepthLevel, bool) {
+	var best DepthLevel
+	found := false
+	for _, l := range b.Bids {
+		if !found || l.Price.GreaterThan(best.Price) {
+			best, found = l, true
+		}
+	}
+	return best, found
+}
+
+// FillableQuantity sums the opposite-side depth an order on side could
+// take without crossing limit; a nil limit takes the whole side.
+func (b OrderBook) FillableQuantity(side OrderSide, limit *Decimal) Decimal {
+	levels, better := b.Asks, -1
+	if side == SideSell {
+		levels, better = b.Bids, 1
+	}
+	var total Decimal
+	for _, l := range levels {
+		if limit == nil || l.Price.Cmp(*limit) == better || l.Price.Equal(*limit) {
+			total = total.Add(l.Quantity)
+		}
+	}
+	return total
+}
+
+// CostToFill estimates the quote an order on side for qty would trade,
+// walking the opposite side best price first. Quantity beyond the book's
+// depth is priced at its last level. It reports false when the opposite
+// side is empty.
+func (b OrderBook) CostToFill(side OrderSide, qty Decimal) (Decimal, bool) {
+	levels := sortedLevels(b.Asks, false)
+	if side == SideSell {
+		levels = sortedLevels(b.Bids, true)
+	}
+	if len(levels) == 0 {
+		return Decimal{}, false
+	}
+	var cost Decimal
+	remaining := qty
+	for _, l := range levels {
+		if !remaining.IsPositive() {
+			break
+		}
+		take := MinDecimal(l.Quantity, remaining)
+		cost = cost.Add(take.Mul(l.Price))
+		remaining = remaining.Sub(take)
+	}
+	if remaining.IsPositive() {
+		cost = cost.Add(remaining.Mul(levels[len(levels)-1].Price))
+	}
+	return cost, true
+}
+
+// BestAsk returns the lowest ask. It does not assume Asks is sorted.
+func (b OrderBook) BestAsk() (DepthLevel, bool) {
+	var best DepthLevel
+	found := false
+	for _, l := range b.Asks {
+		if !found || l.Price.LessThan(best.Price) {
+			best, found = l, true
+		}
+	}
+	return best, found
+}
+
+// Mid returns the midpoint of the best bid and ask.
+func (b OrderBook) Mid() (Decimal, bool) {
+	bid, okBid := b.BestBid()
+	ask, okAsk := b.BestAsk()
+	if !okBid || !okAsk {
+		return Decimal{}, false
+	}
+	return bid.Price.Add(ask.Price).Div(NewDecimalFromInt(2), midScale, RoundHalfEven), true
+}
+
+// midScale is the precision of OrderBook.Mid.
+const midScale = 12
@@ -0,0 +1,72 @@
+package domain
+
+import "strings"
+
+// OrderStatus is the exchange-independent lifecycle state of an order.
+type OrderStatus string
+
+const (
+	StatusNew             OrderStatus = "NEW"
+	StatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	StatusFilled          OrderStatus = "FILLED"
+	StatusCanceled        OrderStatus = "CANCELED"
+	StatusRejected        OrderStatus = "REJECTED"
+	StatusExpired         OrderStatus = "EXPIRED"
+)
+
+// orderTransitions lists the states each state may move to. Final states
+// have no entry.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	StatusNew:             {StatusPartiallyFilled, StatusFilled, StatusCanceled, StatusRejected, StatusExpired},
+	StatusPartiallyFilled: {StatusPartiallyFilled, StatusFilled, StatusCanceled, StatusExpired},
+}
+
+// IsFinal reports whether no further transitions are possible.
+func (s OrderStatus) IsFinal() bool {
+	_, ok := orderTransitions[s]
+	return !ok
+}
+
+// CanTransitionTo reports whether an order in state s may next be observed
+// in state next. Observing the same state again is always allowed.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveOrderStatus maps an exchange status through table (keyed in lower
+// case). Open orders are refined by their filled quantity, and statuses
+// missing from the table are inferred from it entirely.
+func ResolveOrderStatus(table map[string]OrderStatus, raw string, qty, filled Decimal) OrderStatus {
+	status, ok := table[strings.ToLower(raw)]
+	if ok && !status.CanTransitionTo(StatusPartiallyFilled) {
+		return status
+	}
+	switch {
+	case filled.IsPositive() && qty.IsPositive() && filled.Cmp(qty) >= 0:
+		return StatusFilled
+	case filled.IsPositive():
+		return StatusPartiallyFilled
+	case ok:
+		return status
+	default:
+		return StatusNew
+	}
+}
+
+// AveragePrice returns quote/base, or zero when nothing was filled.
+func AveragePrice(quote, base Decimal) Decimal {
+	if !base.IsPositive() {
+		return Zero
+	}
+	return quote.Div(base, averagePriceScale, RoundHalfEven)
+}
+
+const averagePriceScale = 12
@@ -0,0 +1,44 @@
+package domain
+
+import "fmt"
+
+// Pre-trade risk rules reported by RiskError.
+const (
+	RiskMaxNotional      = "max_notional"
+	RiskMaxPosition      = "max_position"
+	RiskPriceCollar      = "price_collar"
+	RiskMaxOpenOrders    = "max_open_orders"
+	RiskDailyLoss        = "daily_loss"
+	RiskSymbolNotAllowed = "symbol_not_allowed"
+	// RiskInsufficientFunds is reported by the balance check, which runs
+	// after the configured rules.
+	RiskInsufficientFunds = "insufficient_funds"
+)
+
+// RiskError reports an order stopped by a pre-trade risk rule. Value is
+// what the order would amount to under the rule and Limit what the rule
+// allows; Asset names their unit where they are amounts.
+type RiskError struct {
+	Rule   string
+	Symbol Symbol
+	Asset  string
+	Value  Decimal
+	Limit  Decimal
+}
+
+func (e *RiskError) Error() string {
+	switch e.Rule {
+	case RiskSymbolNotAllowed:
+		return fmt.Sprintf("%s: trading %s is not allowed", e.Rule, e.Symbol)
+	case RiskPriceCollar:
+		return fmt.Sprintf("%s: price is %s away from mid, more than %s", e.Rule, e.Value, e.Limit)
+	case RiskMaxOpenOrders:
+		return fmt.Sprintf("%s: %s already has %s open orders, the maximum is %s", e.Rule, e.Symbol, e.Value, e.Limit)
+	case RiskInsufficientFunds:
+		return fmt.Sprintf("%s: need %s %s, %s available", e.Rule, e.Value, e.Asset, e.Limit)
+	case RiskDailyLoss:
+		return fmt.Sprintf("%s: today's loss of %s %s has reached the limit %s", e.Rule, e.Value, e.Asset, e.Limit)
+	default:
+		return fmt.Sprintf("%s: %s %s would exceed the limit %s", e.Rule, e.Value, e.Asset, e.Limit)
+	}
+}
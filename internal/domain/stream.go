@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"context"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BookUpdate is one change to an order book. A Snapshot replaces the whole
+// book; otherwise each level sets the quantity at its price and a zero
+// quantity removes the level. Sequence increases by exactly one per update
+// of a symbol, so a consumer can verify it has missed nothing since the
+// last snapshot. Checksum, when non-zero, is the OrderBook.Checksum of
+// the publisher's book after the update.
+type BookUpdate struct {
+	Symbol   Symbol
+	Sequence uint64
+	Snapshot bool
+	Bids     []DepthLevel
+	Asks     []DepthLevel
+	Checksum uint32
+	Time     time.Time
+}
+
+// MarketStreamPort pushes public market data. Each subscription delivers
+// on its own channel, which is closed once ctx is done. Book subscriptions
+// start with a snapshot; a consumer that falls too far behind has its book
+// channel closed and should subscribe again.
+type MarketStreamPort interface {
+	SubscribeBook(ctx context.Context, symbol Symbol) (<-chan BookUpdate, error)
+
+	SubscribeTrades(ctx context.Context, symbol Symbol) (<-chan PublicTrade, error)
+
+	SubscribeTicker(ctx context.Context, symbol Symbol) (<-chan Ticker, error)
+}
+
+type UserEventType string
+
+const (
+	UserEventOrder   UserEventType = "ORDER"
+	UserEventFill    UserEventType = "FILL"
+	UserEventBalance UserEventType = "BALANCE"
+)
+
+// UserEvent is a change to the account: an order changed status or fill,
+// one of our orders traded, or a balance moved. Exactly one of Order,
+// Trade and Balance is set, matching Type.
+type UserEvent struct {
+	Type    UserEventType
+	Order   *OrderResponse `json:",omitempty"`
+	Trade   *Trade         `json:",omitempty"`
+	Balance *Balance       `json:",omitempty"`
+	Time    time.Time
+	Account string `json:",omitempty"`
+}
+
+// UserStreamPort pushes private account events. The channel is closed once
+// ctx is done, or when the consumer falls too far behind, in which case it
+// should reconcile over REST and subscribe again.
+type UserStreamPort interface {
+	SubscribeUser(ctx context.Context) (<-chan UserEvent, error)
+}
+
+// Apply folds u into b. Bids stay sorted best (highest) first and asks
+// best (lowest) first.
+func (b *OrderBook) Apply(u BookUpdate) {
+	if u.Snapshot {
+		b.Symbol = u.Symbol
+		b.Bids = append([]DepthLevel(nil), u.Bids...)
+		b.Asks = append([]DepthLevel(nil), u.Asks...)
+		sortLevels(b.Bids, true)
+		sortLevels(b.Asks, false)
+		return
+	}
+	for _, lvl := range u.Bids {
+		b.Bids = setLevel(b.Bids, lvl, true)
+	}
+	for _, lvl := range u.Asks {
+		b.Asks = setLevel(b.Asks, lvl, false)
+	}
+}
+
+// Copy returns a deep copy of b's level slices.
+func (b OrderBook) Copy() OrderBook {
+	return OrderBook{
+		Symbol: b.Symbol,
+		Bids:   append([]DepthLevel(nil), b.Bids...),
+		Asks:   append([]DepthLevel(nil), b.Asks...),
+	}
+}
+
+// ChecksumDepth is the number of levels per side covered by Checksum.
+const ChecksumDepth = 25
+
+// Checksum is a CRC-32 (IEEE) of the top ChecksumDepth levels of a sorted
+// book, taken over "bidPrice:bidQty:askPrice:askQty:..." with the sides
+// interleaved level by level.
+func (b OrderBook) Checksum() uint32 {
+	var sb strings.Builder
+	for i := 0; i < ChecksumDepth; i++ {
+		for _, side := range [][]DepthLevel{b.Bids, b.Asks} {
+			if i >= len(side) {
+				continue
+			}
+			if sb.Len() > 0 {
+				sb.WriteByte(':')
+			}
+			sb.WriteString(side[i].Price.String())
+			sb.WriteByte(':')
+			sb.WriteString(side[i].Quantity.String())
+		}
+	}
+	return crc32.ChecksumIEEE([]byte(sb.String()))
+}
+
+func sortLevels(levels []DepthLevel, desc bool) {
+	sort.Slice(levels, func(i, j int) bool {
+		if desc {
+			return levels[i].Price.GreaterThan(levels[j].Price)
+		}
+		return levels[i].Price.LessThan(levels[j].Price)
+	})
+}
+
+// setLevel replaces, inserts or (for a zero quantity) removes lvl in the
+// sorted side levels.
+func setLevel(levels []DepthLevel, lvl DepthLevel, desc bool) []DepthLevel {
+	i := sort.Search(len(levels), func(i int) bool {
+		c := levels[i].Price.Cmp(lvl.Price)
+		if desc {
+			return c <= 0
+		}
+		return c >= 0
+	})
+	found := i < len(levels) && levels[i].Price.Equal(lvl.Price)
+	switch {
+	case !lvl.Quantity.IsPositive():
+		if found {
+			levels = append(levels[:i], levels[i+1:]...)
+		}
+	case found:
+		levels[i].Quantity = lvl.Quantity
+	default:
+		levels = append(levels, DepthLevel{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = lvl
+	}
+	return levels
+}
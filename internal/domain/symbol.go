@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Symbol is the exchange-independent name of a market. Its canonical
+// text form is "BASE-QUOTE", e.g. "BTC-IRT".
+type Symbol struct {
+	Base  string
+	Quote string
+}
+
+// assetAliases maps exchange-specific asset codes onto canonical ones.
+// Iranian exchanges quote Toman as either IRT or TMN.
+var assetAliases = map[string]string{
+	"TMN": "IRT",
+}
+
+// CanonicalAsset upper-cases an asset code and resolves known aliases.
+func CanonicalAsset(asset string) string {
+	asset = strings.ToUpper(strings.TrimSpace(asset))
+	if alias, ok := assetAliases[asset]; ok {
+		return alias
+	}
+	return asset
+}
+
+func NewSymbol(base, quote string) Symbol {
+	return Symbol{Base: CanonicalAsset(base), Quote: CanonicalAsset(quote)}
+}
+
+// ParseSymbol accepts "BTC-IRT", "BTC_IRT" or "BTC/IRT" in any case.
+func ParseSymbol(s string) (Symbol, error) {
+	i := strings.IndexAny(s, "-_/")
+	if i <= 0 || i == len(s)-1 || strings.ContainsAny(s[i+1:], "-_/") {
+		return Symbol{}, fmt.Errorf("invalid symbol %q: want BASE-QUOTE", s)
+	}
+	return NewSymbol(s[:i], s[i+1:]), nil
+}
+
+func (s Symbol) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	return s.Base + "-" + s.Quote
+}
+
+func (s Symbol) IsZero() bool { return s.Base == "" && s.Quote == "" }
+
+func (s Symbol) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Symbol) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = Symbol{}
+		return nil
+	}
+	v, err := ParseSymbol(string(text))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// SymbolTranslator converts between canonical symbols and the native
+// market names of one exchange.
+type SymbolTranslator interface {
+	ToExchange(s Symbol) string
+	FromExchange(native string) (Symbol, error)
+}
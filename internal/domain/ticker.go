@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Ticker is a 24-hour summary of one market. Fields the exchange does not
+// publish are left zero. ChangePercent is the 24h price change in percent.
+type Ticker struct {
+	Symbol        Symbol
+	Last          Decimal
+	Bid           Decimal
+	Ask           Decimal
+	High          Decimal
+	Low           Decimal
+	Volume        Decimal
+	QuoteVolume   Decimal
+	ChangePercent Decimal
+	Time          time.Time
+}
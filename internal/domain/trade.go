@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+type Liquidity string
+
+const (
+	LiquidityMaker Liquidity = "MAKER"
+	LiquidityTaker Liquidity = "TAKER"
+)
+
+// Trade is a single execution (fill) of one of our orders.
+// Liquidity is empty when the exchange does not report it.
+type Trade struct {
+	ID        string
+	OrderID   string
+	Symbol    Symbol
+	Side      OrderSide
+	Price     Decimal
+	Quantity  Decimal
+	Fee       Decimal
+	FeeAsset  string
+	Liquidity Liquidity
+	Time      time.Time
+	Account   string `json:",omitempty"`
+}
+
+// PublicTrade is one execution on a market's public tape. Side is the
+// taker's side; ID is empty when the exchange does not number trades.
+type PublicTrade struct {
+	ID       string
+	Symbol   Symbol
+	Side     OrderSide
+	Price    Decimal
+	Quantity Decimal
+	Time     time.Time
+}
+
+// HistoryFilter selects a page of historical orders or trades.
+// Zero From/To leave that end of the range open; a nil Symbol means
+// every market. Offset and Limit count the rows the exchange lists, and
+// exchanges that cannot filter by time return rows outside From/To, so
+// callers apply Contains themselves.
+type HistoryFilter struct {
+	Symbol *Symbol
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// Contains reports whether t falls inside the filter's time range.
+func (f HistoryFilter) Contains(t time.Time) bool {
+	if !f.From.IsZero() && t.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && t.After(f.To) {
+		return false
+	}
+	return true
+}
@@ -1,26 +1,118 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"trade/internal/domain"
 )
 
-type BitpinConfig struct {
+// defaultAccount names the account of an exchange configured without an
+// account list.
+const defaultAccount = "default"
+
+type BitpinAccount struct {
+	Name      string
 	APIKey    string
 	APISecret string
-	BaseURL   string
+}
+
+type BitpinConfig struct {
+	// Accounts holds one entry per key pair; the first is the default.
+	Accounts []BitpinAccount
+	BaseURL  string
+	WSURL    string
+	// TakerFee is the fee rate of taker fills, e.g. 0.002 for 0.2%.
+	TakerFee domain.Decimal
+}
+
+type WallexAccount struct {
+	Name   string
+	APIKey string
 }
 
 type WallexConfig struct {
-	APIKey  string
-	BaseURL string
+	// Accounts holds one entry per API key; the first is the default.
+	Accounts []WallexAccount
+	BaseURL  string
+	WSURL    string
+	// TakerFee is the fee rate of taker fills, e.g. 0.002 for 0.2%.
+	TakerFee domain.Decimal
+}
+
+// RiskConfig holds the pre-trade risk limits. Empty maps, a zero
+// PriceCollar and a zero MaxOpenOrders leave that rule off.
+type RiskConfig struct {
+	// MaxNotional caps one order's value, keyed by quote asset.
+	MaxNotional map[string]domain.Decimal
+	// MaxPosition caps the holding of an asset after a buy.
+	MaxPosition map[string]domain.Decimal
+	// PriceCollar is the furthest a limit price may be from mid, as a
+	// fraction.
+	PriceCollar domain.Decimal
+	// MaxOpenOrders caps open orders per market and account.
+	MaxOpenOrders int
+	// DailyLoss stops trading a quote asset after losing this much today.
+	DailyLoss map[string]domain.Decimal
+	// AllowSymbols, when set, are the only markets that may be traded;
+	// DenySymbols may never be.
+	AllowSymbols []domain.Symbol
+	DenySymbols  []domain.Symbol
 }
 
 type Config struct {
-	Exchange string // "bitpin", or "wallex"
-	HTTPPort string
-	LogLevel string
+	// Exchanges lists the exchanges to connect to, "bitpin" and/or
+	// "wallex". The first one serves requests that do not name one.
+	Exchanges []string
+	HTTPPort  string
+	LogLevel  string
+
+	MarketsTTL time.Duration
+	// StopPollInterval is how often emulated stop orders check the book.
+	StopPollInterval time.Duration
+	// StrictPrecision rejects orders off the tick/step grid instead of rounding them.
+	StrictPrecision bool
+	// CancelConcurrency bounds parallel cancels when cancelling all orders.
+	CancelConcurrency int
+	// BatchConcurrency bounds parallel placements of a batch order request.
+	BatchConcurrency int
+	// UserPollInterval is how often account state is polled on exchanges
+	// without a private websocket.
+	UserPollInterval time.Duration
+	// BookIdleTimeout is how long a local order book is kept current
+	// after it was last read.
+	BookIdleTimeout time.Duration
+	// CheckFunds rejects orders the free balance cannot cover before they
+	// are sent, using balances cached for BalanceCacheTTL.
+	CheckFunds      bool
+	BalanceCacheTTL time.Duration
+
+	// ArbSymbols lists the markets the arbitrage scanner compares across
+	// exchanges; empty disables it.
+	ArbSymbols  []domain.Symbol
+	ArbInterval time.Duration
+	// ArbMinSpread is the smallest net spread reported, as a fraction.
+	ArbMinSpread domain.Decimal
+	// ArbTransferCosts is the cost of moving each asset between
+	// exchanges, in units of that asset.
+	ArbTransferCosts map[string]domain.Decimal
+	// ArbExecute trades the opportunities found, for ArbAccount.
+	ArbExecute bool
+	ArbAccount string
+
+	Risk RiskConfig
+
+	// TradingHalted starts with trading halted. HaltStateFile, when set,
+	// keeps the halt state across restarts.
+	TradingHalted bool
+	HaltStateFile string
+	// AdminToken guards the admin API; empty disables it.
+	AdminToken string
 
 	Bitpin BitpinConfig
 	Wallex WallexConfig
@@ -29,22 +121,233 @@ type Config struct {
 func LoadConfig() (*Config, error) {
 	_ = godotenv.Load()
 
-	return &Config{
-		Exchange: getEnv("EXCHANGE", "bitpin"),
-		HTTPPort: getEnv("HTTP_PORT", "8080"),
-		LogLevel: getEnv("LOG_LEVEL", "info"),
-
-		Bitpin: BitpinConfig{
-			APIKey:    mustGetEnv("BITPIN_API_KEY"),
-			APISecret: mustGetEnv("BITPIN_API_SECRET"),
-			BaseURL:   getEnv("BITPIN_BASE_URL", "https://api.bitpin.ir"),
-		},
-
-		Wallex: WallexConfig{
-			APIKey:  mustGetEnv("WALLEX_API_KEY"),
-			BaseURL: getEnv("WALLEX_BASE_URL", "https://api.wallex.ir"),
-		},
-	}, nil
+	marketsTTL, err := time.ParseDuration(getEnv("MARKETS_TTL", "10m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MARKETS_TTL: %w", err)
+	}
+	stopPoll, err := time.ParseDuration(getEnv("STOP_POLL_INTERVAL", "1s"))
+	if err != nil || stopPoll <= 0 {
+		return nil, fmt.Errorf("invalid STOP_POLL_INTERVAL: %q", os.Getenv("STOP_POLL_INTERVAL"))
+	}
+
+	userPoll, err := time.ParseDuration(getEnv("USER_POLL_INTERVAL", "2s"))
+	if err != nil || userPoll <= 0 {
+		return nil, fmt.Errorf("invalid USER_POLL_INTERVAL: %q", os.Getenv("USER_POLL_INTERVAL"))
+	}
+	bookIdle, err := time.ParseDuration(getEnv("BOOK_IDLE_TIMEOUT", "10m"))
+	if err != nil || bookIdle <= 0 {
+		return nil, fmt.Errorf("invalid BOOK_IDLE_TIMEOUT: %q", os.Getenv("BOOK_IDLE_TIMEOUT"))
+	}
+	balanceTTL, err := time.ParseDuration(getEnv("BALANCE_CACHE_TTL", "2s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BALANCE_CACHE_TTL: %w", err)
+	}
+	cancelConcurrency, err := strconv.Atoi(getEnv("CANCEL_CONCURRENCY", "4"))
+	if err != nil || cancelConcurrency < 1 {
+		return nil, fmt.Errorf("invalid CANCEL_CONCURRENCY: %q", os.Getenv("CANCEL_CONCURRENCY"))
+	}
+
+	batchConcurrency, err := strconv.Atoi(getEnv("BATCH_CONCURRENCY", "4"))
+	if err != nil || batchConcurrency < 1 {
+		return nil, fmt.Errorf("invalid BATCH_CONCURRENCY: %q", os.Getenv("BATCH_CONCURRENCY"))
+	}
+
+	arbSymbols, err := symbolList("ARB_SYMBOLS")
+	if err != nil {
+		return nil, err
+	}
+	arbInterval, err := time.ParseDuration(getEnv("ARB_INTERVAL", "1s"))
+	if err != nil || arbInterval <= 0 {
+		return nil, fmt.Errorf("invalid ARB_INTERVAL: %q", os.Getenv("ARB_INTERVAL"))
+	}
+	arbMinSpread, err := domain.ParseDecimal(getEnv("ARB_MIN_SPREAD", "0.001"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ARB_MIN_SPREAD: %w", err)
+	}
+	arbTransferCosts, err := assetAmounts(os.Getenv("ARB_TRANSFER_COSTS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ARB_TRANSFER_COSTS: %w", err)
+	}
+
+	risk, err := loadRiskConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	exchanges := splitList(getEnv("EXCHANGES", getEnv("EXCHANGE", "bitpin")))
+	if len(exchanges) == 0 {
+		return nil, fmt.Errorf("invalid EXCHANGES: %q", os.Getenv("EXCHANGES"))
+	}
+
+	cfg := &Config{
+		Exchanges: exchanges,
+		HTTPPort:  getEnv("HTTP_PORT", "8080"),
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+
+		MarketsTTL:       marketsTTL,
+		StrictPrecision:  getEnv("ORDER_ROUNDING", "round") == "reject",
+		StopPollInterval: stopPoll,
+
+		CancelConcurrency: cancelConcurrency,
+		BatchConcurrency:  batchConcurrency,
+		UserPollInterval:  userPoll,
+		BookIdleTimeout:   bookIdle,
+		CheckFunds:        getEnv("CHECK_FUNDS", "true") == "true",
+		BalanceCacheTTL:   balanceTTL,
+
+		ArbSymbols:       arbSymbols,
+		ArbInterval:      arbInterval,
+		ArbMinSpread:     arbMinSpread,
+		ArbTransferCosts: arbTransferCosts,
+		ArbExecute:       getEnv("ARB_EXECUTE", "false") == "true",
+		ArbAccount:       os.Getenv("ARB_ACCOUNT"),
+
+		Risk: risk,
+
+		TradingHalted: getEnv("TRADING_HALTED", "false") == "true",
+		HaltStateFile: os.Getenv("HALT_STATE_FILE"),
+		AdminToken:    os.Getenv("ADMIN_TOKEN"),
+	}
+
+	// Credentials are only required for the exchanges in use.
+	for _, name := range exchanges {
+		switch name {
+		case "bitpin":
+			fee, err := feeRate("BITPIN_TAKER_FEE")
+			if err != nil {
+				return nil, err
+			}
+			cfg.Bitpin = BitpinConfig{
+				BaseURL:  getEnv("BITPIN_BASE_URL", "https://api.bitpin.ir"),
+				WSURL:    getEnv("BITPIN_WS_URL", "wss://ws.bitpin.ir/connection/websocket"),
+				TakerFee: fee,
+			}
+			for _, account := range accountNames("BITPIN") {
+				cfg.Bitpin.Accounts = append(cfg.Bitpin.Accounts, BitpinAccount{
+					Name:      account,
+					APIKey:    mustGetEnv(accountEnv("BITPIN", account, "API_KEY")),
+					APISecret: mustGetEnv(accountEnv("BITPIN", account, "API_SECRET")),
+				})
+			}
+		case "wallex":
+			fee, err := feeRate("WALLEX_TAKER_FEE")
+			if err != nil {
+				return nil, err
+			}
+			cfg.Wallex = WallexConfig{
+				BaseURL:  getEnv("WALLEX_BASE_URL", "https://api.wallex.ir"),
+				WSURL:    getEnv("WALLEX_WS_URL", "wss://api.wallex.ir/socket.io/?EIO=4&transport=websocket"),
+				TakerFee: fee,
+			}
+			for _, account := range accountNames("WALLEX") {
+				cfg.Wallex.Accounts = append(cfg.Wallex.Accounts, WallexAccount{
+					Name:   account,
+					APIKey: mustGetEnv(accountEnv("WALLEX", account, "API_KEY")),
+				})
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// loadRiskConfig reads the RISK_* variables; every rule is off by default.
+func loadRiskConfig() (RiskConfig, error) {
+	var risk RiskConfig
+	var err error
+	for key, dst := range map[string]*map[string]domain.Decimal{
+		"RISK_MAX_NOTIONAL": &risk.MaxNotional,
+		"RISK_MAX_POSITION": &risk.MaxPosition,
+		"RISK_DAILY_LOSS":   &risk.DailyLoss,
+	} {
+		if *dst, err = assetAmounts(os.Getenv(key)); err != nil {
+			return RiskConfig{}, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	risk.PriceCollar, err = domain.ParseDecimal(getEnv("RISK_PRICE_COLLAR", "0"))
+	if err != nil || risk.PriceCollar.IsNegative() {
+		return RiskConfig{}, fmt.Errorf("invalid RISK_PRICE_COLLAR: %q", os.Getenv("RISK_PRICE_COLLAR"))
+	}
+	risk.MaxOpenOrders, err = strconv.Atoi(getEnv("RISK_MAX_OPEN_ORDERS", "0"))
+	if err != nil || risk.MaxOpenOrders < 0 {
+		return RiskConfig{}, fmt.Errorf("invalid RISK_MAX_OPEN_ORDERS: %q", os.Getenv("RISK_MAX_OPEN_ORDERS"))
+	}
+	if risk.AllowSymbols, err = symbolList("RISK_ALLOW_SYMBOLS"); err != nil {
+		return RiskConfig{}, err
+	}
+	if risk.DenySymbols, err = symbolList("RISK_DENY_SYMBOLS"); err != nil {
+		return RiskConfig{}, err
+	}
+	return risk, nil
+}
+
+// symbolList reads a comma-separated list of symbols from key.
+func symbolList(key string) ([]domain.Symbol, error) {
+	var out []domain.Symbol
+	for _, raw := range splitList(os.Getenv(key)) {
+		symbol, err := domain.ParseSymbol(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		out = append(out, symbol)
+	}
+	return out, nil
+}
+
+// accountNames reads <exchange>_ACCOUNTS, a comma-separated list of account
+// names. Without it the exchange has the single account "default".
+func accountNames(exchange string) []string {
+	if names := splitList(os.Getenv(exchange + "_ACCOUNTS")); len(names) > 0 {
+		return names
+	}
+	return []string{defaultAccount}
+}
+
+// accountEnv names the variable holding key for an account:
+// BITPIN_API_KEY for the default account, BITPIN_DESK_2_API_KEY for
+// "desk-2".
+func accountEnv(exchange, account, key string) string {
+	if account == defaultAccount {
+		return exchange + "_" + key
+	}
+	name := strings.ToUpper(strings.ReplaceAll(account, "-", "_"))
+	return exchange + "_" + name + "_" + key
+}
+
+// feeRate reads a fee as a fraction in [0, 1); unset means no fee.
+func feeRate(key string) (domain.Decimal, error) {
+	fee, err := domain.ParseDecimal(getEnv(key, "0"))
+	if err != nil || fee.IsNegative() || fee.Cmp(domain.NewDecimalFromInt(1)) >= 0 {
+		return domain.Decimal{}, fmt.Errorf("invalid %s: %q", key, os.Getenv(key))
+	}
+	return fee, nil
+}
+
+// assetAmounts parses "BTC:0.0002,USDT:1" into amounts keyed by canonical
+// asset.
+func assetAmounts(s string) (map[string]domain.Decimal, error) {
+	out := make(map[string]domain.Decimal)
+	for _, item := range splitList(s) {
+		asset, amount, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q: want ASSET:AMOUNT", item)
+		}
+		d, err := domain.ParseDecimal(strings.TrimSpace(amount))
+		if err != nil || d.IsNegative() {
+			return nil, fmt.Errorf("%q: invalid amount", item)
+		}
+		out[domain.CanonicalAsset(asset)] = d
+	}
+	return out, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
 }
 
 func getEnv(key, def string) string {
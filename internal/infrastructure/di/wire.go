@@ -1,16 +1,20 @@
 package di
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/gofiber/fiber/v2"
 
 	"trade/internal/adapters/bitpin"
+	"trade/internal/adapters/filestore"
 	"trade/internal/adapters/logger"
 	"trade/internal/adapters/wallex"
 	"trade/internal/application"
 	"trade/internal/domain"
 	"trade/internal/infrastructure/config"
+	"trade/internal/orderbook"
+	"trade/internal/ports"
 	"trade/pkg/transport"
 )
 
@@ -20,28 +24,130 @@ func BuildApp(cfg *config.Config) (*fiber.App, error) {
 		return nil, err
 	}
 
-	var exch domain.ExchangePort
-	switch cfg.Exchange {
+	ctx := context.Background()
+	var exchanges []*application.Exchange
+	for _, name := range cfg.Exchanges {
+		x, err := buildExchange(ctx, name, cfg, logPort)
+		if err != nil {
+			return nil, err
+		}
+		exchanges = append(exchanges, x)
+	}
+	registry, err := application.NewExchangeRegistry(exchanges, logPort)
+	if err != nil {
+		return nil, err
+	}
+
+	var haltStore domain.HaltStorePort
+	if cfg.HaltStateFile != "" {
+		haltStore = filestore.NewHaltFile(cfg.HaltStateFile)
+	}
+
+	svc := application.NewTradingService(registry, application.ServiceConfig{
+		CancelConcurrency: cfg.CancelConcurrency,
+		BatchConcurrency:  cfg.BatchConcurrency,
+		Risk:              application.NewRiskEngine(riskRules(cfg.Risk)...),
+		CheckFunds:        cfg.CheckFunds,
+		BalanceTTL:        cfg.BalanceCacheTTL,
+		Halted:            cfg.TradingHalted,
+		HaltStore:         haltStore,
+	}, logPort)
+
+	hub := application.NewStreamHub(registry, logPort)
+
+	arb := application.NewArbitrageScanner(ctx, registry, svc, application.ArbitrageConfig{
+		Symbols:       cfg.ArbSymbols,
+		Interval:      cfg.ArbInterval,
+		MinSpread:     cfg.ArbMinSpread,
+		TransferCosts: cfg.ArbTransferCosts,
+		Execute:       cfg.ArbExecute,
+		Account:       cfg.ArbAccount,
+	}, logPort)
+
+	app := transport.NewRouter(svc, hub, arb, cfg.AdminToken, logPort)
+	return app, nil
+}
+
+// riskRules builds the configured pre-trade rules, cheapest first.
+func riskRules(cfg config.RiskConfig) []application.RiskRule {
+	var rules []application.RiskRule
+	if len(cfg.AllowSymbols) > 0 || len(cfg.DenySymbols) > 0 {
+		rules = append(rules, application.NewSymbolListRule(cfg.AllowSymbols, cfg.DenySymbols))
+	}
+	if len(cfg.MaxNotional) > 0 {
+		rules = append(rules, application.NewMaxNotionalRule(cfg.MaxNotional))
+	}
+	if cfg.PriceCollar.IsPositive() {
+		rules = append(rules, application.NewPriceCollarRule(cfg.PriceCollar))
+	}
+	if len(cfg.MaxPosition) > 0 {
+		rules = append(rules, application.NewMaxPositionRule(cfg.MaxPosition))
+	}
+	if cfg.MaxOpenOrders > 0 {
+		rules = append(rules, application.NewMaxOpenOrdersRule(cfg.MaxOpenOrders))
+	}
+	if len(cfg.DailyLoss) > 0 {
+		rules = append(rules, application.NewDailyLossRule(cfg.DailyLoss))
+	}
+	return rules
+}
+
+// buildExchange connects to one exchange with each of its accounts and
+// starts the services that run on them. Public data goes through the
+// first account's connection.
+func buildExchange(ctx context.Context, name string, cfg *config.Config, logPort ports.LoggerPort) (*application.Exchange, error) {
+	x := &application.Exchange{Name: name}
+	// acctLogs holds each account's logger, in x.Accounts order.
+	var acctLogs []ports.LoggerPort
+	switch name {
 	case "bitpin":
-		exch = bitpin.NewAdapter(
-			cfg.Bitpin.APIKey,
-			cfg.Bitpin.APISecret,
-			cfg.Bitpin.BaseURL,
-			logPort,
-		)
+		var public *bitpin.BitpinAdapter
+		for _, acct := range cfg.Bitpin.Accounts {
+			acctLog := logger.WithFields(logPort, ports.Fields{"exchange": name, "account": acct.Name})
+			acctLogs = append(acctLogs, acctLog)
+			adapter := bitpin.NewAdapter(acct.APIKey, acct.APISecret, cfg.Bitpin.BaseURL, acctLog)
+			if public == nil {
+				public = adapter
+			}
+			x.Accounts = append(x.Accounts, &application.Account{
+				Name: acct.Name,
+				API:  adapter,
+				User: bitpin.NewUserStream(ctx, cfg.Bitpin.WSURL, adapter, acctLog),
+			})
+		}
+		if public == nil {
+			return nil, fmt.Errorf("exchange %s: no accounts configured", name)
+		}
+		x.API = public
+		x.Market = bitpin.NewMarketStream(ctx, cfg.Bitpin.WSURL, public, logPort)
+		x.TakerFee = cfg.Bitpin.TakerFee
 
 	case "wallex":
-		exch = wallex.NewAdapter(
-			cfg.Wallex.APIKey,
-			cfg.Wallex.BaseURL,
-			logPort,
-		)
+		for _, acct := range cfg.Wallex.Accounts {
+			acctLog := logger.WithFields(logPort, ports.Fields{"exchange": name, "account": acct.Name})
+			acctLogs = append(acctLogs, acctLog)
+			adapter := wallex.NewAdapter(acct.APIKey, cfg.Wallex.BaseURL, acctLog)
+			x.Accounts = append(x.Accounts, &application.Account{
+				Name: acct.Name,
+				API:  adapter,
+				// Wallex has no private websocket.
+				User: application.NewPollingUserStream(ctx, adapter, cfg.UserPollInterval, acctLog),
+			})
+		}
+		if len(x.Accounts) == 0 {
+			return nil, fmt.Errorf("exchange %s: no accounts configured", name)
+		}
+		x.API = x.Accounts[0].API
+		x.Market = wallex.NewMarketStream(ctx, cfg.Wallex.WSURL, logPort)
+		x.TakerFee = cfg.Wallex.TakerFee
 	default:
-		return nil, fmt.Errorf("unsupported exchange: %s", cfg.Exchange)
+		return nil, fmt.Errorf("unsupported exchange: %s", name)
 	}
 
-	svc := application.NewTradingService(exch, logPort)
-
-	app := transport.NewRouter(svc, logPort)
-	return app, nil
+	x.Books = orderbook.NewManager(ctx, x.API, x.Market, cfg.BookIdleTimeout, logPort)
+	x.Markets = application.NewMarketRegistry(x.API, cfg.MarketsTTL, cfg.StrictPrecision, logPort)
+	for i, acct := range x.Accounts {
+		acct.Stops = application.NewStopOrderManager(ctx, acct.API, x.Books, cfg.StopPollInterval, acctLogs[i])
+	}
+	return x, nil
 }
@@ -0,0 +1,205 @@
+// Package orderbook keeps local copies of exchange order books, seeded over
+// REST and kept current from the market stream, so that every component
+// can read the same up-to-date book without asking the exchange.
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+)
+
+var (
+	// ErrGap is returned by Apply when an update does not follow the last
+	// one applied; the book must be resynchronised.
+	ErrGap = errors.New("orderbook: sequence gap")
+	// ErrChecksum is returned by Apply when the book no longer matches the
+	// publisher's checksum; the book must be resynchronised.
+	ErrChecksum = errors.New("orderbook: checksum mismatch")
+)
+
+// Book is one symbol's sorted local order book. It is safe for concurrent
+// use.
+type Book struct {
+	mu   sync.RWMutex
+	book domain.OrderBook
+	seq  uint64
+	// live is set once a stream snapshot has been applied; until then the
+	// book only holds a REST seed and cannot take diffs.
+	live    bool
+	seeded  bool
+	updated time.Time
+}
+
+func NewBook(symbol domain.Symbol) *Book {
+	return &Book{book: domain.OrderBook{Symbol: symbol}}
+}
+
+// Seed replaces the book with a REST snapshot. The book then waits for a
+// stream snapshot before it accepts diffs again.
+func (b *Book) Seed(book domain.OrderBook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.book.Apply(domain.BookUpdate{Symbol: b.book.Symbol, Snapshot: true, Bids: book.Bids, Asks: book.Asks})
+	b.seq, b.live, b.seeded = 0, false, true
+	b.updated = time.Now()
+}
+
+// Apply folds one stream update into the book. A diff must carry the next
+// sequence after the last update applied and, when the update has one,
+// leave the book matching its checksum. On error the book stops taking
+// diffs until the next snapshot.
+func (b *Book) Apply(u domain.BookUpdate) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !u.Snapshot {
+		if !b.live {
+			return fmt.Errorf("%w: diff %d before any snapshot", ErrGap, u.Sequence)
+		}
+		if u.Sequence != b.seq+1 {
+			b.live = false
+			return fmt.Errorf("%w: expected %d, got %d", ErrGap, b.seq+1, u.Sequence)
+		}
+	}
+
+	u.Symbol = b.book.Symbol
+	b.book.Apply(u)
+	b.seq, b.live, b.seeded = u.Sequence, true, true
+	b.updated = time.Now()
+
+	if u.Checksum != 0 {
+		if sum := b.book.Checksum(); sum != u.Checksum {
+			b.live = false
+			return fmt.Errorf("%w: sequence %d: expected %08x, got %08x", ErrChecksum, u.Sequence, u.Checksum, sum)
+		}
+	}
+	return nil
+}
+
+// Symbol returns the book's market.
+func (b *Book) Symbol() domain.Symbol {
+	return b.book.Symbol
+}
+
+// Sequence returns the stream sequence of the last update applied, zero
+// after a REST seed.
+func (b *Book) Sequence() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.seq
+}
+
+// Live reports whether the book is following the stream.
+func (b *Book) Live() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.live
+}
+
+// Ready reports whether the book holds any data yet.
+func (b *Book) Ready() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.seeded
+}
+
+// UpdatedAt returns when the book last changed.
+func (b *Book) UpdatedAt() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.updated
+}
+
+// BestBid returns the highest bid.
+func (b *Book) BestBid() (domain.DepthLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.book.Bids) == 0 {
+		return domain.DepthLevel{}, false
+	}
+	return b.book.Bids[0], true
+}
+
+// BestAsk returns the lowest ask.
+func (b *Book) BestAsk() (domain.DepthLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.book.Asks) == 0 {
+		return domain.DepthLevel{}, false
+	}
+	return b.book.Asks[0], true
+}
+
+// Depth returns a copy of the best n levels of each side, best first; n
+// <= 0 returns the whole book.
+func (b *Book) Depth(n int) domain.OrderBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return domain.OrderBook{
+		Symbol: b.book.Symbol,
+		Bids:   topLevels(b.book.Bids, n),
+		Asks:   topLevels(b.book.Asks, n),
+	}
+}
+
+// VolumeToPrice returns the quantity an order on side could take from the
+// opposite side without crossing limit.
+func (b *Book) VolumeToPrice(side domain.OrderSide, limit domain.Decimal) domain.Decimal {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	levels, better := b.opposite(side)
+	var total domain.Decimal
+	for _, l := range levels {
+		if c := l.Price.Cmp(limit); c != better && c != 0 {
+			break
+		}
+		total = total.Add(l.Quantity)
+	}
+	return total
+}
+
+// PriceForVolume walks the opposite side for an order on side of qty and
+// returns the volume-weighted average price and the worst price it would
+// reach. ok is false if the book is too thin to fill qty.
+func (b *Book) PriceForVolume(side domain.OrderSide, qty domain.Decimal) (avg, worst domain.Decimal, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	levels, _ := b.opposite(side)
+	var filled, notional domain.Decimal
+	for _, l := range levels {
+		if !filled.LessThan(qty) {
+			break
+		}
+		take := l.Quantity
+		if rest := qty.Sub(filled); rest.LessThan(take) {
+			take = rest
+		}
+		filled = filled.Add(take)
+		notional = notional.Add(take.Mul(l.Price))
+		worst = l.Price
+	}
+	if filled.IsZero() || filled.LessThan(qty) {
+		return domain.Decimal{}, domain.Decimal{}, false
+	}
+	return domain.AveragePrice(notional, filled), worst, true
+}
+
+// opposite returns the levels an order on side trades against and the
+// Cmp result of a price better than a limit on that side. Callers hold
+// b.mu.
+func (b *Book) opposite(side domain.OrderSide) ([]domain.DepthLevel, int) {
+	if side == domain.SideSell {
+		return b.book.Bids, 1
+	}
+	return b.book.Asks, -1
+}
+
+func topLevels(levels []domain.DepthLevel, n int) []domain.DepthLevel {
+	if n > 0 && n < len(levels) {
+		levels = levels[:n]
+	}
+	return append([]domain.DepthLevel(nil), levels...)
+}
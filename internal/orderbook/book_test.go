@@ -0,0 +1,277 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"trade/internal/domain"
+)
+
+var btcIRT = domain.NewSymbol("BTC", "IRT")
+
+func lvl(price, qty string) domain.DepthLevel {
+	return domain.DepthLevel{Price: domain.MustParseDecimal(price), Quantity: domain.MustParseDecimal(qty)}
+}
+
+func dec(s string) domain.Decimal { return domain.MustParseDecimal(s) }
+
+func snapshot(seq uint64) domain.BookUpdate {
+	return domain.BookUpdate{
+		Sequence: seq,
+		Snapshot: true,
+		Bids:     []domain.DepthLevel{lvl("99", "1"), lvl("100", "2"), lvl("98", "3")},
+		Asks:     []domain.DepthLevel{lvl("102", "2"), lvl("101", "1"), lvl("103", "5")},
+	}
+}
+
+func checkSide(t *testing.T, name string, got []domain.DepthLevel, want ...domain.DepthLevel) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d levels %v, want %v", name, len(got), got, want)
+	}
+	for i := range want {
+		if !got[i].Price.Equal(want[i].Price) || !got[i].Quantity.Equal(want[i].Quantity) {
+			t.Fatalf("%s[%d] = %s@%s, want %s@%s", name, i, got[i].Quantity, got[i].Price, want[i].Quantity, want[i].Price)
+		}
+	}
+}
+
+func TestBookSnapshotSortsAndReplaces(t *testing.T) {
+	b := NewBook(btcIRT)
+	if err := b.Apply(snapshot(10)); err != nil {
+		t.Fatal(err)
+	}
+	d := b.Depth(0)
+	if d.Symbol != btcIRT {
+		t.Errorf("symbol = %s", d.Symbol)
+	}
+	checkSide(t, "bids", d.Bids, lvl("100", "2"), lvl("99", "1"), lvl("98", "3"))
+	checkSide(t, "asks", d.Asks, lvl("101", "1"), lvl("102", "2"), lvl("103", "5"))
+	if b.Sequence() != 10 || !b.Live() || !b.Ready() {
+		t.Errorf("seq=%d live=%v ready=%v", b.Sequence(), b.Live(), b.Ready())
+	}
+
+	if err := b.Apply(domain.BookUpdate{Sequence: 20, Snapshot: true, Bids: []domain.DepthLevel{lvl("90", "1")}}); err != nil {
+		t.Fatal(err)
+	}
+	d = b.Depth(0)
+	checkSide(t, "bids", d.Bids, lvl("90", "1"))
+	checkSide(t, "asks", d.Asks)
+	if b.Sequence() != 20 {
+		t.Errorf("seq = %d, want 20", b.Sequence())
+	}
+}
+
+func TestBookDiffs(t *testing.T) {
+	b := NewBook(btcIRT)
+	if err := b.Apply(snapshot(1)); err != nil {
+		t.Fatal(err)
+	}
+	updates := []domain.BookUpdate{
+		{Sequence: 2, Bids: []domain.DepthLevel{lvl("100", "5")}},                                // replace
+		{Sequence: 3, Bids: []domain.DepthLevel{lvl("99.5", "1")}},                               // insert
+		{Sequence: 4, Asks: []domain.DepthLevel{lvl("101", "0"), lvl("104", "1")}},               // remove, append
+		{Sequence: 5, Bids: []domain.DepthLevel{lvl("97", "0")}},                                 // remove missing
+		{Sequence: 6, Asks: []domain.DepthLevel{lvl("100.5", "2")}, Bids: []domain.DepthLevel{}}, // new best
+	}
+	for _, u := range updates {
+		if err := b.Apply(u); err != nil {
+			t.Fatalf("seq %d: %v", u.Sequence, err)
+		}
+	}
+	d := b.Depth(0)
+	checkSide(t, "bids", d.Bids, lvl("100", "5"), lvl("99.5", "1"), lvl("99", "1"), lvl("98", "3"))
+	checkSide(t, "asks", d.Asks, lvl("100.5", "2"), lvl("102", "2"), lvl("103", "5"), lvl("104", "1"))
+	if b.Sequence() != 6 {
+		t.Errorf("seq = %d, want 6", b.Sequence())
+	}
+}
+
+func TestBookGap(t *testing.T) {
+	b := NewBook(btcIRT)
+	if err := b.Apply(domain.BookUpdate{Sequence: 1, Bids: []domain.DepthLevel{lvl("1", "1")}}); !errors.Is(err, ErrGap) {
+		t.Fatalf("diff before snapshot: err = %v, want ErrGap", err)
+	}
+	if b.Ready() {
+		t.Error("book ready without data")
+	}
+
+	if err := b.Apply(snapshot(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Apply(domain.BookUpdate{Sequence: 7, Bids: []domain.DepthLevel{lvl("100", "9")}}); !errors.Is(err, ErrGap) {
+		t.Fatalf("skipped sequence: err = %v, want ErrGap", err)
+	}
+	if b.Live() {
+		t.Error("book still live after a gap")
+	}
+	if got, _ := b.BestBid(); !got.Quantity.Equal(dec("2")) {
+		t.Errorf("gapped diff was applied: best bid %s@%s", got.Quantity, got.Price)
+	}
+	// Nothing is accepted, even the expected sequence, until a snapshot.
+	if err := b.Apply(domain.BookUpdate{Sequence: 6}); !errors.Is(err, ErrGap) {
+		t.Fatalf("diff after gap: err = %v, want ErrGap", err)
+	}
+	if err := b.Apply(snapshot(8)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Apply(domain.BookUpdate{Sequence: 9}); err != nil {
+		t.Fatalf("diff after resync: %v", err)
+	}
+	if !b.Live() {
+		t.Error("book not live after resync")
+	}
+
+	// Replayed and stale sequences are gaps too.
+	if err := b.Apply(domain.BookUpdate{Sequence: 9}); !errors.Is(err, ErrGap) {
+		t.Fatalf("replayed sequence: err = %v, want ErrGap", err)
+	}
+}
+
+func TestBookSeedWaitsForSnapshot(t *testing.T) {
+	b := NewBook(btcIRT)
+	b.Seed(domain.OrderBook{Bids: []domain.DepthLevel{lvl("1", "1"), lvl("2", "1")}, Asks: []domain.DepthLevel{lvl("3", "1")}})
+	if !b.Ready() || b.Live() || b.Sequence() != 0 {
+		t.Fatalf("after seed: ready=%v live=%v seq=%d", b.Ready(), b.Live(), b.Sequence())
+	}
+	if bid, ok := b.BestBid(); !ok || !bid.Price.Equal(dec("2")) {
+		t.Errorf("best bid = %v %v", bid, ok)
+	}
+	if err := b.Apply(domain.BookUpdate{Sequence: 1}); !errors.Is(err, ErrGap) {
+		t.Fatalf("diff after seed: err = %v, want ErrGap", err)
+	}
+	if err := b.Apply(snapshot(3)); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Live() {
+		t.Error("not live after snapshot")
+	}
+}
+
+func TestBookChecksum(t *testing.T) {
+	b := NewBook(btcIRT)
+	snap := snapshot(1)
+	want := domain.OrderBook{}
+	want.Apply(snap)
+	snap.Checksum = want.Checksum()
+	if err := b.Apply(snap); err != nil {
+		t.Fatalf("matching checksum: %v", err)
+	}
+
+	diff := domain.BookUpdate{Sequence: 2, Bids: []domain.DepthLevel{lvl("100", "4")}}
+	want.Apply(diff)
+	diff.Checksum = want.Checksum()
+	if err := b.Apply(diff); err != nil {
+		t.Fatalf("matching checksum: %v", err)
+	}
+
+	bad := domain.BookUpdate{Sequence: 3, Asks: []domain.DepthLevel{lvl("101", "7")}, Checksum: want.Checksum()}
+	if err := b.Apply(bad); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("err = %v, want ErrChecksum", err)
+	}
+	if b.Live() {
+		t.Error("book still live after a checksum mismatch")
+	}
+	if err := b.Apply(domain.BookUpdate{Sequence: 4}); !errors.Is(err, ErrGap) {
+		t.Fatalf("diff after mismatch: err = %v, want ErrGap", err)
+	}
+}
+
+func TestBookDepthQueries(t *testing.T) {
+	b := NewBook(btcIRT)
+	if _, ok := b.BestBid(); ok {
+		t.Error("empty book has a best bid")
+	}
+	if _, ok := b.BestAsk(); ok {
+		t.Error("empty book has a best ask")
+	}
+	if err := b.Apply(snapshot(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if bid, _ := b.BestBid(); !bid.Price.Equal(dec("100")) {
+		t.Errorf("best bid = %s", bid.Price)
+	}
+	if ask, _ := b.BestAsk(); !ask.Price.Equal(dec("101")) {
+		t.Errorf("best ask = %s", ask.Price)
+	}
+	d := b.Depth(2)
+	checkSide(t, "bids", d.Bids, lvl("100", "2"), lvl("99", "1"))
+	checkSide(t, "asks", d.Asks, lvl("101", "1"), lvl("102", "2"))
+	d.Bids[0].Quantity = dec("999")
+	if bid, _ := b.BestBid(); !bid.Quantity.Equal(dec("2")) {
+		t.Error("Depth returned the book's own levels")
+	}
+	if d := b.Depth(10); len(d.Bids) != 3 || len(d.Asks) != 3 {
+		t.Errorf("Depth(10) = %d bids, %d asks", len(d.Bids), len(d.Asks))
+	}
+}
+
+func TestBookVolumeToPrice(t *testing.T) {
+	b := NewBook(btcIRT)
+	if err := b.Apply(snapshot(1)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		side  domain.OrderSide
+		limit string
+		want  string
+	}{
+		{domain.SideBuy, "100", "0"},
+		{domain.SideBuy, "101", "1"},
+		{domain.SideBuy, "102.5", "3"},
+		{domain.SideBuy, "1000", "8"},
+		{domain.SideSell, "101", "0"},
+		{domain.SideSell, "100", "2"},
+		{domain.SideSell, "98.5", "3"},
+		{domain.SideSell, "1", "6"},
+	}
+	for _, tt := range tests {
+		if got := b.VolumeToPrice(tt.side, dec(tt.limit)); !got.Equal(dec(tt.want)) {
+			t.Errorf("VolumeToPrice(%s, %s) = %s, want %s", tt.side, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestBookPriceForVolume(t *testing.T) {
+	b := NewBook(btcIRT)
+	if err := b.Apply(snapshot(1)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		side       domain.OrderSide
+		qty        string
+		avg, worst string
+		ok         bool
+	}{
+		{domain.SideBuy, "1", "101", "101", true},
+		// 1@101 + 1@102 = 203 / 2
+		{domain.SideBuy, "2", "101.5", "102", true},
+		// 1@101 + 2@102 + 1@103 = 408 / 4
+		{domain.SideBuy, "4", "102", "103", true},
+		{domain.SideBuy, "8", "", "", true},
+		{domain.SideBuy, "8.1", "", "", false},
+		{domain.SideSell, "0.5", "100", "100", true},
+		// 2@100 + 1@99 = 299 / 3
+		{domain.SideSell, "3", "99.666666666667", "99", true},
+		{domain.SideSell, "7", "", "", false},
+	}
+	for _, tt := range tests {
+		avg, worst, ok := b.PriceForVolume(tt.side, dec(tt.qty))
+		if ok != tt.ok {
+			t.Errorf("PriceForVolume(%s, %s) ok = %v, want %v", tt.side, tt.qty, ok, tt.ok)
+			continue
+		}
+		if tt.avg == "" {
+			continue
+		}
+		if !avg.Equal(dec(tt.avg)) || !worst.Equal(dec(tt.worst)) {
+			t.Errorf("PriceForVolume(%s, %s) = %s, %s, want %s, %s", tt.side, tt.qty, avg, worst, tt.avg, tt.worst)
+		}
+	}
+
+	empty := NewBook(btcIRT)
+	if _, _, ok := empty.PriceForVolume(domain.SideBuy, dec("1")); ok {
+		t.Error("empty book filled an order")
+	}
+}
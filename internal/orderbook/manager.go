@@ -0,0 +1,213 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+// syncTimeout bounds how long Book waits for a new book's first data.
+const syncTimeout = 5 * time.Second
+
+// maxSeedAge is how old a book that is not following the stream may be
+// before OrderBook falls back to REST.
+const maxSeedAge = 2 * time.Second
+
+// stableAfter is how long a book must have followed the stream for its
+// next resync to start without delay again.
+const stableAfter = time.Minute
+
+// ErrNotSynced is returned by Book when the book has no data in time.
+var ErrNotSynced = errors.New("orderbook: book not synchronised")
+
+// ErrSeedFailed is returned by Book when a new book's first REST
+// snapshot fails, e.g. for a symbol the exchange does not list.
+var ErrSeedFailed = errors.New("orderbook: snapshot failed")
+
+// tracked is one book and the goroutine keeping it current. ready is
+// closed once the book holds data or, with err set, once its first seed
+// failed.
+type tracked struct {
+	book     *Book
+	cancel   context.CancelFunc
+	ready    chan struct{}
+	err      error
+	lastUsed time.Time
+}
+
+// Manager keeps one Book per symbol for as long as it is being read.
+// Books are started on first use, kept current from the market stream
+// and resynchronised from REST whenever the stream skips an update; a
+// book nobody has read for the idle timeout is dropped.
+type Manager struct {
+	exchange domain.ExchangePort
+	stream   domain.MarketStreamPort
+	idle     time.Duration
+	ctx      context.Context
+	log      ports.LoggerPort
+
+	mu    sync.Mutex
+	books map[domain.Symbol]*tracked
+}
+
+// NewManager starts the idle sweeper; it and every tracked book run until
+// ctx is done.
+func NewManager(ctx context.Context, exch domain.ExchangePort, stream domain.MarketStreamPort, idle time.Duration, log ports.LoggerPort) *Manager {
+	m := &Manager{
+		exchange: exch,
+		stream:   stream,
+		idle:     idle,
+		ctx:      ctx,
+		log:      log,
+		books:    make(map[domain.Symbol]*tracked),
+	}
+	go m.sweep(ctx)
+	return m
+}
+
+// Book returns the local book of symbol, starting to track it if needed,
+// once it holds data. It fails with ErrSeedFailed as soon as a new book
+// cannot be seeded; the next call tries again.
+func (m *Manager) Book(ctx context.Context, symbol domain.Symbol) (*Book, error) {
+	m.mu.Lock()
+	t, ok := m.books[symbol]
+	if !ok {
+		trackCtx, cancel := context.WithCancel(m.ctx)
+		t = &tracked{book: NewBook(symbol), cancel: cancel, ready: make(chan struct{})}
+		m.books[symbol] = t
+		go m.track(trackCtx, t)
+	}
+	t.lastUsed = time.Now()
+	m.mu.Unlock()
+
+	timer := time.NewTimer(syncTimeout)
+	defer timer.Stop()
+	select {
+	case <-t.ready:
+		if t.err != nil {
+			return nil, t.err
+		}
+		return t.book, nil
+	case <-timer.C:
+		return nil, ErrNotSynced
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// OrderBook returns a copy of symbol's local book, or the exchange's book
+// when the local one is not ready in time or has lost the stream for a
+// while. A book whose seed just failed is not fetched again.
+func (m *Manager) OrderBook(ctx context.Context, symbol domain.Symbol) (domain.OrderBook, error) {
+	b, err := m.Book(ctx, symbol)
+	switch {
+	case errors.Is(err, ErrSeedFailed):
+		return domain.OrderBook{}, err
+	case err == nil && (b.Live() || time.Since(b.UpdatedAt()) < maxSeedAge):
+		return b.Depth(0), nil
+	}
+	return m.exchange.GetOrderBook(ctx, symbol)
+}
+
+// track seeds t's book over REST, follows the stream and starts over
+// whenever the stream ends or an update does not apply. The first resync
+// is immediate; a book that keeps failing backs off. If the very first
+// seed fails the book is dropped and its waiters get the error.
+func (m *Manager) track(ctx context.Context, t *tracked) {
+	symbol := t.book.Symbol()
+	var backoff time.Duration
+	var once sync.Once
+	markReady := func() { once.Do(func() { close(t.ready) }) }
+
+	for first := true; ctx.Err() == nil; first = false {
+		started := time.Now()
+		if snap, err := m.exchange.GetOrderBook(ctx, symbol); err == nil {
+			t.book.Seed(snap)
+			markReady()
+		} else {
+			m.log.Error(ctx, "orderbook: snapshot failed", ports.Fields{"symbol": symbol, "error": err})
+			if first {
+				m.drop(t, fmt.Errorf("%w: %s: %w", ErrSeedFailed, symbol, err))
+				return
+			}
+		}
+		m.follow(ctx, t, markReady)
+
+		if time.Since(started) > stableAfter {
+			backoff = 0
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		switch {
+		case backoff == 0:
+			backoff = time.Second
+		case backoff < 30*time.Second:
+			backoff *= 2
+		}
+	}
+}
+
+// drop stops tracking t and releases its waiters with err.
+func (m *Manager) drop(t *tracked, err error) {
+	m.mu.Lock()
+	if m.books[t.book.Symbol()] == t {
+		delete(m.books, t.book.Symbol())
+	}
+	m.mu.Unlock()
+	t.cancel()
+	t.err = err
+	close(t.ready)
+}
+
+// follow applies stream updates to t's book until the stream ends or an
+// update is rejected.
+func (m *Manager) follow(ctx context.Context, t *tracked, markReady func()) {
+	symbol := t.book.Symbol()
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates, err := m.stream.SubscribeBook(subCtx, symbol)
+	if err != nil {
+		m.log.Error(ctx, "orderbook: subscribe failed", ports.Fields{"symbol": symbol, "error": err})
+		return
+	}
+
+	for u := range updates {
+		if err := t.book.Apply(u); err != nil {
+			m.log.Error(ctx, "orderbook: resyncing", ports.Fields{"symbol": symbol, "error": err})
+			return
+		}
+		markReady()
+	}
+	if ctx.Err() == nil {
+		m.log.Info(ctx, "orderbook: stream ended, resyncing", ports.Fields{"symbol": symbol})
+	}
+}
+
+// sweep drops books nobody has read for the idle timeout.
+func (m *Manager) sweep(ctx context.Context) {
+	ticker := time.NewTicker(m.idle / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.mu.Lock()
+		for symbol, t := range m.books {
+			if time.Since(t.lastUsed) > m.idle {
+				t.cancel()
+				delete(m.books, symbol)
+				m.log.Info(ctx, "orderbook: released idle book", ports.Fields{"symbol": symbol})
+			}
+		}
+		m.mu.Unlock()
+	}
+}
@@ -0,0 +1,53 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"trade/internal/adapters/fake"
+	"trade/internal/domain"
+	"trade/internal/ports"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(context.Context, string, ports.Fields)  {}
+func (nopLogger) Error(context.Context, string, ports.Fields) {}
+func (nopLogger) Debug(context.Context, string, ports.Fields) {}
+
+func TestManagerSeedFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := fake.NewExchange()
+	m := NewManager(ctx, f, f, time.Minute, nopLogger{})
+
+	start := time.Now()
+	if _, err := m.Book(ctx, btcIRT); !errors.Is(err, ErrSeedFailed) {
+		t.Fatalf("unknown symbol: err = %v, want ErrSeedFailed", err)
+	}
+	if _, err := m.OrderBook(ctx, btcIRT); !errors.Is(err, ErrSeedFailed) {
+		t.Fatalf("OrderBook: err = %v, want ErrSeedFailed", err)
+	}
+	if waited := time.Since(start); waited >= syncTimeout {
+		t.Errorf("waited %s for a failed seed", waited)
+	}
+	m.mu.Lock()
+	n := len(m.books)
+	m.mu.Unlock()
+	if n != 0 {
+		t.Errorf("%d books still tracked", n)
+	}
+
+	// Once the market is listed, the next read starts over.
+	f.AddMarket(domain.Market{Symbol: btcIRT, Tradable: true})
+	f.SetBook(domain.OrderBook{Symbol: btcIRT, Bids: []domain.DepthLevel{lvl("100", "1")}})
+	b, err := m.Book(ctx, btcIRT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bid, ok := b.BestBid(); !ok || !bid.Price.Equal(dec("100")) {
+		t.Errorf("best bid = %v %v", bid, ok)
+	}
+}
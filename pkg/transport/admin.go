@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"trade/internal/application"
+	"trade/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HaltRequest turns the trading kill switch on or off. Operator names who
+// is asking and is logged with the change; CancelAll, when halting, also
+// cancels every open order on every exchange and account.
+type HaltRequest struct {
+	Halted    bool   `json:"halted"`
+	Reason    string `json:"reason" example:"runaway strategy"`
+	Operator  string `json:"operator" example:"alice"`
+	CancelAll bool   `json:"cancel_all"`
+}
+
+// HaltResponse is the kill switch state after a change, with the outcome
+// of the cancels it triggered.
+type HaltResponse struct {
+	State    domain.HaltState      `json:"state"`
+	Canceled []domain.CancelResult `json:"canceled,omitempty"`
+}
+
+// adminAuth admits requests carrying the admin token as a bearer token.
+// Without a configured token the admin API is closed.
+func adminAuth(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "admin API disabled: ADMIN_TOKEN is not set"})
+		}
+		given, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Error: "invalid admin token"})
+		}
+		return c.Next()
+	}
+}
+
+// getHaltHandler reports the kill switch state.
+// @Summary Get trading halt state
+// @Description Report whether trading is halted, why, by whom and since when.
+// @Tags admin
+// @Produce application/json
+// @Param Authorization header string true "Bearer admin token"
+// @Success 200 {object} domain.HaltState
+// @Failure 401 {object} transport.ErrorResponse
+// @Failure 403 {object} transport.ErrorResponse
+// @Router /v1/admin/halt [get]
+func getHaltHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(svc.HaltState())
+	}
+}
+
+// setHaltHandler turns the kill switch on or off.
+// @Summary Halt or resume trading
+// @Description Halt or resume trading on every exchange. While halted new orders are rejected with 503 and emulated stop orders do not fire; cancels still work. With cancel_all every open order is cancelled as well. The state is saved to HALT_STATE_FILE when configured and survives restarts.
+// @Tags admin
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string true "Bearer admin token"
+// @Param halt body transport.HaltRequest true "New state"
+// @Success 200 {object} transport.HaltResponse
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 401 {object} transport.ErrorResponse
+// @Failure 403 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse "Applied but not saved"
+// @Router /v1/admin/halt [post]
+func setHaltHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req HaltRequest
+		if err := c.BodyParser(&req); err != nil {
+			return badRequest(c, "", err)
+		}
+		if req.Operator == "" {
+			return badRequest(c, "operator", errors.New("operator is required"))
+		}
+		if req.CancelAll && !req.Halted {
+			return badRequest(c, "cancel_all", errors.New("cancel_all is only allowed when halting"))
+		}
+
+		by := req.Operator + "@" + c.IP()
+		state, err := svc.SetHalt(c.UserContext(), req.Halted, by, req.Reason)
+		if err != nil {
+			return writeError(c, err)
+		}
+		resp := HaltResponse{State: state}
+		if req.CancelAll {
+			resp.Canceled = svc.CancelEverything(c.UserContext())
+		}
+		return c.JSON(resp)
+	}
+}
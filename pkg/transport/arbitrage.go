@@ -0,0 +1,25 @@
+package transport
+
+import (
+	"trade/internal/application"
+	"trade/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// listArbitrageHandler lists the current cross-exchange opportunities.
+// @Summary List arbitrage opportunities
+// @Description List the price differences between exchanges found by the last scan of the ARB_SYMBOLS markets whose net spread, after taker fees and transfer cost, reaches ARB_MIN_SPREAD. Best net spread first; empty when the scanner is not configured.
+// @Tags market
+// @Produce application/json
+// @Success 200 {array} domain.ArbitrageOpportunity
+// @Router /v1/arbitrage [get]
+func listArbitrageHandler(arb *application.ArbitrageScanner) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		opportunities := arb.Opportunities()
+		if opportunities == nil {
+			opportunities = []domain.ArbitrageOpportunity{}
+		}
+		return c.JSON(opportunities)
+	}
+}
@@ -0,0 +1,120 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+
+	"trade/internal/application"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exchangeHeader selects the exchange of a request made without an
+// exchange path prefix.
+const exchangeHeader = "X-Exchange"
+
+// accountHeader selects the account for order, balance and history
+// calls; the account query parameter does the same where headers cannot
+// be set, e.g. for server-sent events.
+const (
+	accountHeader = "X-Account"
+	accountQuery  = "account"
+)
+
+// exchangeLocal and accountLocal carry the selection to websocket
+// handlers, which only see the request's locals.
+const (
+	exchangeLocal = "exchange"
+	accountLocal  = "account"
+)
+
+// ExchangeList names the configured exchanges and their accounts.
+type ExchangeList struct {
+	Default   string              `json:"default" example:"bitpin"`
+	Exchanges []string            `json:"exchanges" example:"bitpin,wallex"`
+	Accounts  map[string][]string `json:"accounts"`
+}
+
+// exchangeFromHeader routes the request to the exchange named in the
+// X-Exchange header, if any.
+func exchangeFromHeader(svc *application.TradingService) fiber.Handler {
+	known := make(map[string]bool)
+	for _, name := range svc.Exchanges() {
+		known[name] = true
+	}
+	return func(c *fiber.Ctx) error {
+		name := c.Get(exchangeHeader)
+		if name == "" {
+			return c.Next()
+		}
+		if !known[name] {
+			return badRequest(c, exchangeHeader, fmt.Errorf("%w: %q", application.ErrUnknownExchange, name))
+		}
+		useExchange(c, name)
+		return c.Next()
+	}
+}
+
+// exchangeFromPath routes requests under the /v1/{exchange} prefix to
+// that exchange; the path wins over the header.
+func exchangeFromPath(name string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		useExchange(c, name)
+		return c.Next()
+	}
+}
+
+func useExchange(c *fiber.Ctx, name string) {
+	c.SetUserContext(application.WithExchange(c.UserContext(), name))
+	c.Locals(exchangeLocal, name)
+}
+
+// accountFromRequest selects the account named by the X-Account header or
+// the account query parameter. Unknown names are rejected by the service,
+// which knows the accounts of the selected exchange.
+func accountFromRequest(c *fiber.Ctx) error {
+	name := c.Get(accountHeader)
+	if name == "" {
+		name = c.Query(accountQuery)
+	}
+	if name != "" {
+		c.SetUserContext(application.WithAccount(c.UserContext(), name))
+		c.Locals(accountLocal, name)
+	}
+	return c.Next()
+}
+
+// streamContext returns a context for a stream session that outlives the
+// request and keeps its exchange and account selection.
+func streamContext(exchange, account interface{}) context.Context {
+	ctx := context.Background()
+	if name, ok := exchange.(string); ok {
+		ctx = application.WithExchange(ctx, name)
+	}
+	if name, ok := account.(string); ok {
+		ctx = application.WithAccount(ctx, name)
+	}
+	return ctx
+}
+
+// listExchangesHandler lists the configured exchanges.
+// @Summary List exchanges
+// @Description List the exchanges this server is connected to and their accounts. Every other endpoint acts on the default exchange unless the request selects one with a /v1/{exchange} path prefix (e.g. /v1/wallex/balance) or the X-Exchange header. Order, balance, history and user-stream calls act for the account named by the X-Account header or the account query parameter, which may be omitted on exchanges with a single account.
+// @Tags exchanges
+// @Produce application/json
+// @Success 200 {object} transport.ExchangeList
+// @Router /v1/exchanges [get]
+func listExchangesHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		names := svc.Exchanges()
+		list := ExchangeList{Default: names[0], Exchanges: names, Accounts: make(map[string][]string, len(names))}
+		for _, name := range names {
+			accounts, err := svc.Accounts(name)
+			if err != nil {
+				return writeError(c, err)
+			}
+			list.Accounts[name] = accounts
+		}
+		return c.JSON(list)
+	}
+}
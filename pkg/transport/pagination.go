@@ -0,0 +1,90 @@
+package transport
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"trade/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// Page is one window of a paginated listing. NextOffset is set when a
+// further page may exist; it counts rows the exchange listed, so a page
+// filtered by time may hold fewer than Limit items and still have a next
+// one.
+type Page[T any] struct {
+	Items      []T  `json:"items"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// newPage builds the page for the rows fetched with filter, dropping
+// those outside its time range, as stamped by at.
+func newPage[T any](rows []T, filter domain.HistoryFilter, at func(T) time.Time) Page[T] {
+	items := []T{}
+	for _, r := range rows {
+		if filter.Contains(at(r)) {
+			items = append(items, r)
+		}
+	}
+	p := Page[T]{Items: items, Limit: filter.Limit, Offset: filter.Offset}
+	if len(rows) >= filter.Limit {
+		next := filter.Offset + len(rows)
+		p.NextOffset = &next
+	}
+	return p
+}
+
+// historyFilter reads symbol, from, to, limit and offset query parameters.
+// It returns the offending parameter name alongside any error.
+func historyFilter(c *fiber.Ctx) (domain.HistoryFilter, string, error) {
+	var f domain.HistoryFilter
+	var err error
+
+	if f.Symbol, err = querySymbol(c, "symbol"); err != nil {
+		return f, "symbol", err
+	}
+	if f.From, err = queryTime(c, "from"); err != nil {
+		return f, "from", err
+	}
+	if f.To, err = queryTime(c, "to"); err != nil {
+		return f, "to", err
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return f, "from", errors.New("from must not be after to")
+	}
+
+	f.Limit = c.QueryInt("limit", defaultPageLimit)
+	if f.Limit <= 0 || f.Limit > maxPageLimit {
+		return f, "limit", errors.New("limit must be between 1 and " + strconv.Itoa(maxPageLimit))
+	}
+	f.Offset = c.QueryInt("offset", 0)
+	if f.Offset < 0 {
+		return f, "offset", errors.New("offset must not be negative")
+	}
+	return f, "", nil
+}
+
+// queryTime accepts RFC 3339 timestamps or Unix seconds.
+func queryTime(c *fiber.Ctx, key string) (time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errors.New(key + " must be RFC 3339 or Unix seconds")
+	}
+	return t, nil
+}
@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"trade/internal/domain"
+)
+
+func TestNewPageCountsFilteredRows(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	rows := []domain.Trade{{Time: day.Add(3 * time.Hour)}, {Time: day.Add(-time.Hour)}, {Time: day.Add(time.Hour)}}
+	at := func(t domain.Trade) time.Time { return t.Time }
+
+	// One of the three rows fetched is before From; the listing may go on.
+	p := newPage(rows, domain.HistoryFilter{From: day, Limit: 3, Offset: 6}, at)
+	if len(p.Items) != 2 {
+		t.Errorf("items = %d, want 2", len(p.Items))
+	}
+	if p.NextOffset == nil || *p.NextOffset != 9 {
+		t.Errorf("next offset = %v, want 9", p.NextOffset)
+	}
+
+	// Fewer rows than the limit end the listing.
+	if p := newPage(rows, domain.HistoryFilter{Limit: 5}, at); p.NextOffset != nil {
+		t.Errorf("next offset = %d on the last page", *p.NextOffset)
+	}
+	if p := newPage[domain.Trade](nil, domain.HistoryFilter{Limit: 5}, at); p.Items == nil {
+		t.Error("items are null")
+	}
+}
@@ -1,11 +1,17 @@
 package transport
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"trade/internal/application"
 	"trade/internal/domain"
+	"trade/internal/orderbook"
 	"trade/internal/ports"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
 // ErrorResponse represents a JSON error response
@@ -20,16 +26,64 @@ import (
 //	    example: "error message"
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Rule  string `json:"rule,omitempty"`
+	Field string `json:"field,omitempty"`
+}
+
+// writeError maps service errors to an HTTP status: order validation
+// failures and unknown or missing exchange and account selections are
+// the caller's fault (400), orders stopped by a risk rule are 422, orders
+// refused while trading is halted are 503 and anything else is a 500.
+func writeError(c *fiber.Ctx, err error) error {
+	var verr *domain.OrderValidationError
+	if errors.As(err, &verr) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error(), Rule: verr.Rule, Field: verr.Field})
+	}
+	var rerr *domain.RiskError
+	if errors.As(err, &rerr) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(ErrorResponse{Error: err.Error(), Rule: rerr.Rule})
+	}
+	if errors.Is(err, application.ErrTradingHalted) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if errors.Is(err, orderbook.ErrSeedFailed) {
+		return c.Status(fiber.StatusBadGateway).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if errors.Is(err, application.ErrUnknownExchange) || errors.Is(err, application.ErrUnknownAccount) || errors.Is(err, application.ErrAccountRequired) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+}
+
+// querySymbol parses an optional symbol query parameter; it returns nil when
+// the parameter is absent.
+func querySymbol(c *fiber.Ctx, key string) (*domain.Symbol, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	symbol, err := domain.ParseSymbol(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &symbol, nil
+}
+
+// badRequest reports a malformed request parameter.
+func badRequest(c *fiber.Ctx, field string, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error(), Field: field})
 }
 
-func NewRouter(svc *application.TradingService, log ports.LoggerPort) *fiber.App {
+// NewRouter builds the HTTP API. adminToken guards /v1/admin; when empty
+// the admin endpoints refuse every request.
+func NewRouter(svc *application.TradingService, hub *application.StreamHub, arb *application.ArbitrageScanner, adminToken string, log ports.LoggerPort) *fiber.App {
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+			return writeError(c, err)
 		},
 	})
 
-	api := app.Group("/v1")
+	app.Use(recover.New())
 	app.Use(func(c *fiber.Ctx) error {
 		log.Info(c.Context(), "HTTP request", ports.Fields{
 			"method": c.Method(),
@@ -37,61 +91,273 @@ func NewRouter(svc *application.TradingService, log ports.LoggerPort) *fiber.App
 		})
 		return c.Next()
 	})
+	api := app.Group("/v1", exchangeFromHeader(svc), accountFromRequest)
+	api.Get("/exchanges", listExchangesHandler(svc))
+	api.Get("/arbitrage", listArbitrageHandler(arb))
+	admin := api.Group("/admin", adminAuth(adminToken))
+	admin.Get("/halt", getHaltHandler(svc))
+	admin.Post("/halt", setHaltHandler(svc))
+	registerRoutes(api, svc, hub)
+	for _, name := range svc.Exchanges() {
+		registerRoutes(api.Group("/"+name, exchangeFromPath(name)), svc, hub)
+	}
+
+	return app
+}
+
+// registerRoutes adds the exchange API under api. It is mounted at /v1 and
+// again under each /v1/{exchange} prefix.
+func registerRoutes(api fiber.Router, svc *application.TradingService, hub *application.StreamHub) {
 	api.Post("/orders", createOrderHandler(svc))
+	api.Post("/orders/batch", createOrdersHandler(svc))
+	api.Get("/orders", listOpenOrdersHandler(svc))
+	api.Delete("/orders", cancelAllHandler(svc))
+	api.Get("/orders/history", orderHistoryHandler(svc))
+	api.Get("/orders/:symbol/:id", getOrderHandler(svc))
 	api.Delete("/orders/:symbol/:id", cancelOrderHandler(svc))
+	api.Get("/trades", tradeHistoryHandler(svc))
+	api.Get("/trades/:symbol/recent", recentTradesHandler(svc))
 	api.Get("/balance", getBalanceHandler(svc))
 	api.Get("/book/:symbol", getOrderBookHandler(svc))
-
-	return app
+	api.Get("/ticker/:symbol", getTickerHandler(svc))
+	api.Get("/tickers", getTickersHandler(svc))
+	api.Get("/candles/:symbol", getCandlesHandler(svc))
+	api.Get("/markets", getMarketsHandler(svc))
+	api.Get("/stream", streamHandler(hub))
 }
 
+// routeSmart is the route query value that splits an order across all
+// exchanges.
+const routeSmart = "smart"
+
 // createOrderHandler parses a JSON body into OrderRequest and calls CreateOrder.
 // @Summary Create a new order
-// @Description Place a new order on the configured exchange
+// @Description Place a new order on the configured exchange. With route=smart a MARKET or LIMIT order is split across all exchanges by best price after taker fees, within each account's free balance; the response is a parent order (ID prefixed route-) whose Children are the orders placed on each exchange.
 // @Tags orders
 // @Accept application/json
 // @Produce application/json
 // @Param order body domain.OrderRequest true "Order payload"
+// @Param route query string false "Set to smart to route across exchanges" Enums(smart)
 // @Success 201 {object} domain.OrderResponse
 // @Failure 400 {object} transport.ErrorResponse
+// @Failure 422 {object} transport.ErrorResponse "Stopped by a pre-trade risk rule, named in rule"
+// @Failure 503 {object} transport.ErrorResponse "Trading is halted"
 // @Failure 500 {object} transport.ErrorResponse
 // @Router /v1/orders [post]
 func createOrderHandler(svc *application.TradingService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var req domain.OrderRequest
 		if err := c.BodyParser(&req); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+			return badRequest(c, "", err)
 		}
 
-		resp, err := svc.CreateOrder(c.Context(), req)
+		ctx := c.UserContext()
+		switch route := c.Query("route"); route {
+		case "":
+		case routeSmart:
+			ctx = application.WithSmartRouting(ctx)
+		default:
+			return badRequest(c, "route", fmt.Errorf("unknown route %q", route))
+		}
+		resp, err := svc.CreateOrder(ctx, req)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+			return writeError(c, err)
 		}
 		return c.Status(fiber.StatusCreated).JSON(resp)
 	}
 }
 
+// maxBatchSize caps the number of orders in one batch request.
+const maxBatchSize = 100
+
+// createOrdersHandler parses a JSON array of OrderRequest and calls CreateOrders.
+// @Summary Create a batch of orders
+// @Description Place up to 100 orders concurrently and report the outcome of each. With all_or_nothing=true nothing is placed unless every order validates, and placed orders are cancelled again if any placement fails.
+// @Tags orders
+// @Accept application/json
+// @Produce application/json
+// @Param orders body []domain.OrderRequest true "Orders to place"
+// @Param all_or_nothing query bool false "Cancel placed orders if any order fails"
+// @Success 201 {array} domain.PlacementResult "Every order was placed"
+// @Success 207 {array} domain.PlacementResult "At least one order failed"
+// @Failure 400 {object} transport.ErrorResponse
+// @Router /v1/orders/batch [post]
+func createOrdersHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var reqs []domain.OrderRequest
+		if err := c.BodyParser(&reqs); err != nil {
+			return badRequest(c, "", err)
+		}
+		if len(reqs) == 0 || len(reqs) > maxBatchSize {
+			return badRequest(c, "", fmt.Errorf("batch must contain 1 to %d orders", maxBatchSize))
+		}
+
+		results := svc.CreateOrders(c.UserContext(), reqs, c.QueryBool("all_or_nothing"))
+		status := fiber.StatusCreated
+		for _, r := range results {
+			if r.Order == nil || r.RolledBack {
+				status = fiber.StatusMultiStatus
+				break
+			}
+		}
+		return c.Status(status).JSON(results)
+	}
+}
+
 // cancelOrderHandler reads symbol and id from the path and calls CancelOrder.
 // @Summary Cancel an existing order
 // @Description Cancel a placed order by symbol and ID
 // @Tags orders
-// @Param symbol path string true "Trading symbol"
+// @Param symbol path string true "Canonical symbol, e.g. BTC-IRT"
 // @Param id path string true "Order ID"
 // @Success 204
+// @Failure 400 {object} transport.ErrorResponse
 // @Failure 500 {object} transport.ErrorResponse
 // @Router /v1/orders/{symbol}/{id} [delete]
 func cancelOrderHandler(svc *application.TradingService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		symbol := c.Params("symbol")
+		symbol, err := domain.ParseSymbol(c.Params("symbol"))
+		if err != nil {
+			return badRequest(c, "symbol", err)
+		}
 		id := c.Params("id")
 
-		if err := svc.CancelOrder(c.Context(), symbol, id); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+		if err := svc.CancelOrder(c.UserContext(), symbol, id); err != nil {
+			return writeError(c, err)
 		}
 		return c.SendStatus(fiber.StatusNoContent)
 	}
 }
 
+// getOrderHandler returns the current state of a single order.
+// @Summary Get an order
+// @Description Fetch the current state of an order by symbol and ID
+// @Tags orders
+// @Param symbol path string true "Canonical symbol, e.g. BTC-IRT"
+// @Param id path string true "Order ID"
+// @Produce application/json
+// @Success 200 {object} domain.OrderResponse
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/orders/{symbol}/{id} [get]
+func getOrderHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		symbol, err := domain.ParseSymbol(c.Params("symbol"))
+		if err != nil {
+			return badRequest(c, "symbol", err)
+		}
+		order, err := svc.GetOrder(c.UserContext(), symbol, c.Params("id"))
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(order)
+	}
+}
+
+// listOpenOrdersHandler lists resting orders, optionally for one symbol.
+// @Summary List open orders
+// @Description List open orders on the configured exchange, optionally filtered by symbol
+// @Tags orders
+// @Param symbol query string false "Canonical symbol, e.g. BTC-IRT"
+// @Produce application/json
+// @Success 200 {array} domain.OrderResponse
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/orders [get]
+func listOpenOrdersHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		symbol, err := querySymbol(c, "symbol")
+		if err != nil {
+			return badRequest(c, "symbol", err)
+		}
+		orders, err := svc.ListOpenOrders(c.UserContext(), symbol)
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(orders)
+	}
+}
+
+// cancelAllHandler cancels every open order, optionally for one symbol.
+// @Summary Cancel all open orders
+// @Description Cancel every open order, optionally only on one symbol, and report the outcome per order
+// @Tags orders
+// @Param symbol query string false "Canonical symbol, e.g. BTC-IRT"
+// @Produce application/json
+// @Success 200 {array} domain.CancelResult
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/orders [delete]
+func cancelAllHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		symbol, err := querySymbol(c, "symbol")
+		if err != nil {
+			return badRequest(c, "symbol", err)
+		}
+		results, err := svc.CancelAll(c.UserContext(), symbol)
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(results)
+	}
+}
+
+// orderHistoryHandler pages through closed orders.
+// @Summary Order history
+// @Description Page through closed orders, optionally filtered by symbol and time range
+// @Tags orders
+// @Param symbol query string false "Canonical symbol, e.g. BTC-IRT"
+// @Param from query string false "Start of range (RFC 3339 or Unix seconds)"
+// @Param to query string false "End of range (RFC 3339 or Unix seconds)"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Items to skip"
+// @Produce application/json
+// @Success 200 {object} transport.Page[domain.OrderResponse]
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/orders/history [get]
+func orderHistoryHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter, field, err := historyFilter(c)
+		if err != nil {
+			return badRequest(c, field, err)
+		}
+		orders, err := svc.GetOrderHistory(c.UserContext(), filter)
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(newPage(orders, filter, func(o domain.OrderResponse) time.Time { return o.Timestamp }))
+	}
+}
+
+// tradeHistoryHandler pages through our fills.
+// @Summary Trade history
+// @Description Page through executed trades (fills), optionally filtered by symbol and time range
+// @Tags orders
+// @Param symbol query string false "Canonical symbol, e.g. BTC-IRT"
+// @Param from query string false "Start of range (RFC 3339 or Unix seconds)"
+// @Param to query string false "End of range (RFC 3339 or Unix seconds)"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Items to skip"
+// @Produce application/json
+// @Success 200 {object} transport.Page[domain.Trade]
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/trades [get]
+func tradeHistoryHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter, field, err := historyFilter(c)
+		if err != nil {
+			return badRequest(c, field, err)
+		}
+		trades, err := svc.GetTrades(c.UserContext(), filter)
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(newPage(trades, filter, func(t domain.Trade) time.Time { return t.Time }))
+	}
+}
+
 // getBalanceHandler calls GetBalance and returns account balances.
 // @Summary Get account balances
 // @Description Retrieve all asset balances of the account
@@ -102,9 +368,9 @@ func cancelOrderHandler(svc *application.TradingService) fiber.Handler {
 // @Router /v1/balance [get]
 func getBalanceHandler(svc *application.TradingService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		balances, err := svc.GetBalance(c.Context())
+		balances, err := svc.GetBalance(c.UserContext())
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+			return writeError(c, err)
 		}
 		return c.JSON(balances)
 	}
@@ -112,20 +378,190 @@ func getBalanceHandler(svc *application.TradingService) fiber.Handler {
 
 // getOrderBookHandler fetches the order book for the given symbol.
 // @Summary Get order book
-// @Description Fetch the current order book for a trading symbol
+// @Description Fetch the current order book for a trading symbol. With aggregate=true the books of every configured exchange are merged into a domain.AggregatedBook whose levels carry each exchange's quantity.
 // @Tags market
-// @Param symbol path string true "Trading symbol"
+// @Param symbol path string true "Canonical symbol, e.g. BTC-IRT"
+// @Param aggregate query bool false "Merge the book across all exchanges"
 // @Produce application/json
 // @Success 200 {object} domain.OrderBook
+// @Failure 400 {object} transport.ErrorResponse
 // @Failure 500 {object} transport.ErrorResponse
+// @Failure 502 {object} transport.ErrorResponse
 // @Router /v1/book/{symbol} [get]
 func getOrderBookHandler(svc *application.TradingService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		symbol := c.Params("symbol")
-		book, err := svc.GetOrderBook(c.Context(), symbol)
+		symbol, err := domain.ParseSymbol(c.Params("symbol"))
+		if err != nil {
+			return badRequest(c, "symbol", err)
+		}
+		if c.QueryBool("aggregate") {
+			agg, err := svc.GetAggregatedOrderBook(c.UserContext(), symbol)
+			if err != nil {
+				return writeError(c, err)
+			}
+			return c.JSON(agg)
+		}
+		book, err := svc.GetOrderBook(c.UserContext(), symbol)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: err.Error()})
+			return writeError(c, err)
 		}
 		return c.JSON(book)
 	}
 }
+
+const (
+	defaultRecentTrades = 100
+	maxRecentTrades     = 500
+)
+
+// recentTradesHandler returns the latest public trades of a market.
+// @Summary Recent public trades
+// @Description The latest trades on a market's public tape, newest first. Side is the taker's side.
+// @Tags market
+// @Param symbol path string true "Canonical symbol, e.g. BTC-IRT"
+// @Param limit query int false "Number of trades (1-500, default 100)"
+// @Produce application/json
+// @Success 200 {array} domain.PublicTrade
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/trades/{symbol}/recent [get]
+func recentTradesHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		symbol, err := domain.ParseSymbol(c.Params("symbol"))
+		if err != nil {
+			return badRequest(c, "symbol", err)
+		}
+		limit := c.QueryInt("limit", defaultRecentTrades)
+		if limit <= 0 || limit > maxRecentTrades {
+			return badRequest(c, "limit", fmt.Errorf("limit must be between 1 and %d", maxRecentTrades))
+		}
+		trades, err := svc.GetRecentTrades(c.UserContext(), symbol, limit)
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(trades)
+	}
+}
+
+// getTickerHandler returns the 24h ticker of one market.
+// @Summary Get ticker
+// @Description Last price, best bid/ask and 24h high, low, volume and change for a trading symbol
+// @Tags market
+// @Param symbol path string true "Canonical symbol, e.g. BTC-IRT"
+// @Produce application/json
+// @Success 200 {object} domain.Ticker
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/ticker/{symbol} [get]
+func getTickerHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		symbol, err := domain.ParseSymbol(c.Params("symbol"))
+		if err != nil {
+			return badRequest(c, "symbol", err)
+		}
+		ticker, err := svc.GetTicker(c.UserContext(), symbol)
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(ticker)
+	}
+}
+
+// getTickersHandler returns the 24h tickers of every market.
+// @Summary List tickers
+// @Description Last price, best bid/ask and 24h statistics for every market
+// @Tags market
+// @Produce application/json
+// @Success 200 {array} domain.Ticker
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/tickers [get]
+func getTickersHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tickers, err := svc.GetTickers(c.UserContext())
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(tickers)
+	}
+}
+
+const (
+	defaultCandleCount = 500
+	maxCandleCount     = 5000
+)
+
+// getCandlesHandler returns OHLCV bars for a symbol. Without from/to it
+// returns the latest 500 bars.
+// @Summary Get candles
+// @Description Historical OHLCV bars for a trading symbol, oldest first. Ranges longer than the exchange allows per request are fetched in pages; at most 5000 bars can be requested at once.
+// @Tags market
+// @Param symbol path string true "Canonical symbol, e.g. BTC-IRT"
+// @Param resolution query string false "Bar width: 1m, 5m, 15m, 30m, 1h, 4h, 1d or 1w (default 1h)"
+// @Param from query string false "Start of range (RFC 3339 or Unix seconds)"
+// @Param to query string false "End of range (RFC 3339 or Unix seconds), default now"
+// @Produce application/json
+// @Success 200 {array} domain.Candle
+// @Failure 400 {object} transport.ErrorResponse
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/candles/{symbol} [get]
+func getCandlesHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		symbol, err := domain.ParseSymbol(c.Params("symbol"))
+		if err != nil {
+			return badRequest(c, "symbol", err)
+		}
+		resolution, err := domain.ParseResolution(c.Query("resolution", string(domain.Resolution1h)))
+		if err != nil {
+			return badRequest(c, "resolution", err)
+		}
+		from, err := queryTime(c, "from")
+		if err != nil {
+			return badRequest(c, "from", err)
+		}
+		to, err := queryTime(c, "to")
+		if err != nil {
+			return badRequest(c, "to", err)
+		}
+
+		width := resolution.Duration()
+		if to.IsZero() {
+			to = time.Now().UTC()
+		}
+		if from.IsZero() {
+			from = to.Add(-defaultCandleCount * width)
+		}
+		if from.After(to) {
+			return badRequest(c, "from", errors.New("from must not be after to"))
+		}
+		if to.Sub(from) > maxCandleCount*width {
+			return badRequest(c, "from", fmt.Errorf("range exceeds %d candles", maxCandleCount))
+		}
+
+		candles, err := svc.GetCandles(c.UserContext(), symbol, resolution, from, to)
+		if err != nil {
+			return writeError(c, err)
+		}
+		if candles == nil {
+			candles = []domain.Candle{}
+		}
+		return c.JSON(candles)
+	}
+}
+
+// getMarketsHandler lists the markets and their trading rules.
+// @Summary List markets
+// @Description List the exchange markets with tick size, step size and minimums
+// @Tags market
+// @Produce application/json
+// @Success 200 {array} domain.Market
+// @Failure 500 {object} transport.ErrorResponse
+// @Router /v1/markets [get]
+func getMarketsHandler(svc *application.TradingService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		markets, err := svc.GetMarkets(c.UserContext())
+		if err != nil {
+			return writeError(c, err)
+		}
+		return c.JSON(markets)
+	}
+}
@@ -0,0 +1,142 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trade/internal/adapters/fake"
+	"trade/internal/application"
+	"trade/internal/domain"
+	"trade/internal/orderbook"
+	"trade/internal/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(context.Context, string, ports.Fields)  {}
+func (nopLogger) Error(context.Context, string, ports.Fields) {}
+func (nopLogger) Debug(context.Context, string, ports.Fields) {}
+
+var btcIRT = domain.NewSymbol("BTC", "IRT")
+
+// newFakeExchange lists BTC-IRT with a 99/101 book and funds the account.
+func newFakeExchange() *fake.Exchange {
+	f := fake.NewExchange()
+	f.AddMarket(domain.Market{Symbol: btcIRT, TickSize: domain.MustParseDecimal("1"), StepSize: domain.MustParseDecimal("0.001"), Tradable: true})
+	f.SetBook(domain.OrderBook{
+		Symbol: btcIRT,
+		Bids:   []domain.DepthLevel{{Price: domain.MustParseDecimal("99"), Quantity: domain.MustParseDecimal("10")}},
+		Asks:   []domain.DepthLevel{{Price: domain.MustParseDecimal("101"), Quantity: domain.MustParseDecimal("10")}},
+	})
+	f.SetBalances(
+		domain.Balance{Asset: "IRT", Free: domain.MustParseDecimal("1000000")},
+		domain.Balance{Asset: "BTC", Free: domain.MustParseDecimal("100")},
+	)
+	return f
+}
+
+// newTestApp serves the named fake exchanges, each with one account.
+func newTestApp(t *testing.T, cfg application.ServiceConfig, fakes map[string]*fake.Exchange, names ...string) *fiber.App {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	log := nopLogger{}
+
+	var exchanges []*application.Exchange
+	for _, name := range names {
+		f := fakes[name]
+		books := orderbook.NewManager(ctx, f, f, time.Minute, log)
+		exchanges = append(exchanges, &application.Exchange{
+			Name:    name,
+			API:     f,
+			Market:  f,
+			Markets: application.NewMarketRegistry(f, time.Minute, false, log),
+			Books:   books,
+			Accounts: []*application.Account{{
+				Name:  "main",
+				API:   f,
+				Stops: application.NewStopOrderManager(ctx, f, books, time.Hour, log),
+			}},
+		})
+	}
+	registry, err := application.NewExchangeRegistry(exchanges, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := application.NewTradingService(registry, cfg, log)
+	return NewRouter(svc, nil, nil, "", log)
+}
+
+func TestSmartOrderOverRiskLimit(t *testing.T) {
+	fakes := map[string]*fake.Exchange{"alpha": newFakeExchange(), "beta": newFakeExchange()}
+	cfg := application.ServiceConfig{
+		CancelConcurrency: 1,
+		BatchConcurrency:  1,
+		BalanceTTL:        time.Second,
+		Risk: application.NewRiskEngine(
+			application.NewMaxNotionalRule(map[string]domain.Decimal{"IRT": domain.MustParseDecimal("1000")}),
+		),
+	}
+	app := newTestApp(t, cfg, fakes, "alpha", "beta")
+
+	// 15 BTC at a mid of 100 is worth 1500, while the parts of 10 and 5
+	// BTC the exchanges would take are each within the limit.
+	body := `{"Symbol":"BTC-IRT","Side":"BUY","Type":"MARKET","Quantity":"15"}`
+	req := httptest.NewRequest("POST", "/v1/orders?route=smart", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", resp.StatusCode, data)
+	}
+	var out ErrorResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Rule != domain.RiskMaxNotional {
+		t.Errorf("rule = %q, want %q", out.Rule, domain.RiskMaxNotional)
+	}
+	for name, f := range fakes {
+		if placed := f.Placed(); len(placed) != 0 {
+			t.Errorf("%s: %d orders placed", name, len(placed))
+		}
+	}
+
+	// Within the limit, the order is split and placed.
+	body = `{"Symbol":"BTC-IRT","Side":"BUY","Type":"MARKET","Quantity":"8"}`
+	req = httptest.NewRequest("POST", "/v1/orders?route=smart", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusCreated {
+		data, _ := io.ReadAll(resp.Body)
+		t.Fatalf("status = %d, want 201: %s", resp.StatusCode, data)
+	}
+}
+
+func TestOrderBookUnknownSymbol(t *testing.T) {
+	fakes := map[string]*fake.Exchange{"alpha": newFakeExchange()}
+	app := newTestApp(t, application.ServiceConfig{BalanceTTL: time.Second}, fakes, "alpha")
+
+	req := httptest.NewRequest("GET", "/v1/book/ETH-IRT", nil)
+	resp, err := app.Test(req, int(time.Second/time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusBadGateway {
+		data, _ := io.ReadAll(resp.Body)
+		t.Fatalf("status = %d, want 502: %s", resp.StatusCode, data)
+	}
+}
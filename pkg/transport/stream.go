@@ -0,0 +1,220 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"trade/internal/application"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// keepAliveInterval is how often idle streams are pinged so that dead
+// clients are noticed and proxies keep the connection open.
+const keepAliveInterval = 15 * time.Second
+
+// StreamCommand is sent by websocket clients to change their channels.
+type StreamCommand struct {
+	Op       string   `json:"op" enums:"subscribe,unsubscribe"`
+	Channels []string `json:"channels" example:"book:BTC-IRT,user"`
+}
+
+// StreamFrame is one message sent to a stream client. Data carries a
+// domain.BookUpdate, domain.Ticker, domain.PublicTrade or domain.UserEvent
+// depending on the channel. Event is "subscribed", "unsubscribed" or
+// "closed"; a closed channel must be subscribed again.
+type StreamFrame struct {
+	Channel string      `json:"channel,omitempty"`
+	Event   string      `json:"event,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// streamSession is one client's set of hub subscriptions, merged onto a
+// single outgoing queue.
+type streamSession struct {
+	hub *application.StreamHub
+	ctx context.Context
+	out chan StreamFrame
+
+	mu   sync.Mutex
+	subs map[string]context.CancelFunc
+}
+
+func newStreamSession(ctx context.Context, hub *application.StreamHub) *streamSession {
+	return &streamSession{hub: hub, ctx: ctx, out: make(chan StreamFrame, 64), subs: make(map[string]context.CancelFunc)}
+}
+
+func (s *streamSession) subscribe(channel string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[channel]; ok {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	msgs, err := s.hub.Subscribe(ctx, channel)
+	if err != nil {
+		cancel()
+		return err
+	}
+	s.subs[channel] = cancel
+
+	go func() {
+		for m := range msgs {
+			select {
+			case s.out <- StreamFrame{Channel: m.Channel, Data: m.Data}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.mu.Lock()
+		delete(s.subs, channel)
+		s.mu.Unlock()
+		cancel()
+		s.send(StreamFrame{Channel: channel, Event: "closed", Error: "stream interrupted, subscribe again"})
+	}()
+	return nil
+}
+
+func (s *streamSession) unsubscribe(channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.subs[channel]; ok {
+		cancel()
+		delete(s.subs, channel)
+	}
+}
+
+func (s *streamSession) send(f StreamFrame) {
+	select {
+	case s.out <- f:
+	case <-s.ctx.Done():
+	}
+}
+
+// streamHandler serves /v1/stream: websocket upgrades get the interactive
+// protocol, plain requests get server-sent events.
+// @Summary Stream market and account updates
+// @Description Server-sent events for the channels listed in `channels`: `book:<SYMBOL>`, `ticker:<SYMBOL>`, `trades:<SYMBOL>` and `user` (private order, fill and balance updates). Each event's data is a transport.StreamFrame. Book channels start with a snapshot followed by updates whose Sequence increases by one. The same path accepts a websocket upgrade; websocket clients send transport.StreamCommand messages to subscribe and unsubscribe.
+// @Tags stream
+// @Param channels query string true "Comma-separated channels, e.g. book:BTC-IRT,user"
+// @Produce text/event-stream
+// @Success 200 {object} transport.StreamFrame
+// @Failure 400 {object} transport.ErrorResponse
+// @Router /v1/stream [get]
+func streamHandler(hub *application.StreamHub) fiber.Handler {
+	ws := websocket.New(func(c *websocket.Conn) { serveWebsocket(c, hub) })
+	return func(c *fiber.Ctx) error {
+		if websocket.IsWebSocketUpgrade(c) {
+			return ws(c)
+		}
+		return serveSSE(c, hub)
+	}
+}
+
+func serveWebsocket(c *websocket.Conn, hub *application.StreamHub) {
+	ctx, cancel := context.WithCancel(streamContext(c.Locals(exchangeLocal), c.Locals(accountLocal)))
+	defer cancel()
+	s := newStreamSession(ctx, hub)
+
+	go func() {
+		defer cancel()
+		for {
+			var cmd StreamCommand
+			if err := c.ReadJSON(&cmd); err != nil {
+				var syntaxErr *json.SyntaxError
+				if errors.As(err, &syntaxErr) {
+					s.send(StreamFrame{Error: "invalid command: " + err.Error()})
+					continue
+				}
+				return
+			}
+			for _, channel := range cmd.Channels {
+				switch cmd.Op {
+				case "subscribe":
+					if err := s.subscribe(channel); err != nil {
+						s.send(StreamFrame{Channel: channel, Error: err.Error()})
+						continue
+					}
+					s.send(StreamFrame{Channel: channel, Event: "subscribed"})
+				case "unsubscribe":
+					s.unsubscribe(channel)
+					s.send(StreamFrame{Channel: channel, Event: "unsubscribed"})
+				default:
+					s.send(StreamFrame{Error: fmt.Sprintf("unknown op %q", cmd.Op)})
+				}
+			}
+		}
+	}()
+
+	ping := time.NewTicker(keepAliveInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case f := <-s.out:
+			if err := c.WriteJSON(f); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(keepAliveInterval)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func serveSSE(c *fiber.Ctx, hub *application.StreamHub) error {
+	var channels []string
+	for _, ch := range strings.Split(c.Query("channels"), ",") {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			channels = append(channels, ch)
+		}
+	}
+	if len(channels) == 0 {
+		return badRequest(c, "channels", errors.New("at least one channel is required"))
+	}
+
+	ctx, cancel := context.WithCancel(streamContext(c.Locals(exchangeLocal), c.Locals(accountLocal)))
+	s := newStreamSession(ctx, hub)
+	for _, channel := range channels {
+		if err := s.subscribe(channel); err != nil {
+			cancel()
+			return badRequest(c, "channels", err)
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		ping := time.NewTicker(keepAliveInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case f := <-s.out:
+				data, _ := json.Marshal(f)
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			case <-ping.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}))
+	return nil
+}